@@ -165,19 +165,9 @@ var updateTypeMap = map[schemes.UpdateType]func(debugRaw string) schemes.UpdateI
 	schemes.TypeChatTitleChanged: func(debugRaw string) schemes.UpdateInterface {
 		return &schemes.ChatTitleChangedUpdate{Update: schemes.Update{DebugRaw: debugRaw}}
 	},
-}
-
-// attachmentTypeMap maps attachment types to their corresponding struct constructors
-var attachmentTypeMap = map[schemes.AttachmentType]func() schemes.AttachmentInterface{
-	schemes.AttachmentAudio:    func() schemes.AttachmentInterface { return new(schemes.AudioAttachment) },
-	schemes.AttachmentContact:  func() schemes.AttachmentInterface { return new(schemes.ContactAttachment) },
-	schemes.AttachmentFile:     func() schemes.AttachmentInterface { return new(schemes.FileAttachment) },
-	schemes.AttachmentImage:    func() schemes.AttachmentInterface { return new(schemes.PhotoAttachment) },
-	schemes.AttachmentKeyboard: func() schemes.AttachmentInterface { return new(schemes.InlineKeyboardAttachment) },
-	schemes.AttachmentLocation: func() schemes.AttachmentInterface { return new(schemes.LocationAttachment) },
-	schemes.AttachmentShare:    func() schemes.AttachmentInterface { return new(schemes.ShareAttachment) },
-	schemes.AttachmentSticker:  func() schemes.AttachmentInterface { return new(schemes.StickerAttachment) },
-	schemes.AttachmentVideo:    func() schemes.AttachmentInterface { return new(schemes.VideoAttachment) },
+	schemes.TypeMessageChatCreated: func(debugRaw string) schemes.UpdateInterface {
+		return &schemes.MessageChatCreatedUpdate{Update: schemes.Update{DebugRaw: debugRaw}}
+	},
 }
 
 // bytesToProperUpdate converts raw JSON bytes to the appropriate update type
@@ -203,67 +193,9 @@ func (a *Api) bytesToProperUpdate(data []byte) (schemes.UpdateInterface, error)
 		return nil, fmt.Errorf("failed to unmarshal update of type %s: %w", updateType, err)
 	}
 
-	if err := a.processMessageAttachments(update); err != nil {
-		return nil, fmt.Errorf("failed to process message attachments: %w", err)
-	}
-
 	return update, nil
 }
 
-// processMessageAttachments processes attachments for message-type updates
-func (a *Api) processMessageAttachments(update schemes.UpdateInterface) error {
-	switch u := update.(type) {
-	case *schemes.MessageCreatedUpdate:
-		if u.Message.Body.RawAttachments != nil {
-			for _, rawAttachment := range u.Message.Body.RawAttachments {
-				attachment, err := a.bytesToProperAttachment([]byte(rawAttachment))
-				if err != nil {
-					return fmt.Errorf("failed to process attachment: %w", err)
-				}
-
-				u.Message.Body.Attachments = append(u.Message.Body.Attachments, attachment)
-			}
-		}
-	case *schemes.MessageEditedUpdate:
-		if u.Message.Body.RawAttachments != nil {
-			for _, rawAttachment := range u.Message.Body.RawAttachments {
-				attachment, err := a.bytesToProperAttachment([]byte(rawAttachment))
-				if err != nil {
-					return fmt.Errorf("failed to process attachment: %w", err)
-				}
-
-				u.Message.Body.Attachments = append(u.Message.Body.Attachments, attachment)
-			}
-		}
-	default:
-		return nil // No attachments to process
-	}
-
-	return nil
-}
-
-// bytesToProperAttachment converts raw JSON bytes to the appropriate attachment type
-func (a *Api) bytesToProperAttachment(data []byte) (schemes.AttachmentInterface, error) {
-	baseAttachment := &schemes.Attachment{}
-	if err := json.Unmarshal(data, baseAttachment); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal base attachment: %w", err)
-	}
-
-	attachmentType := baseAttachment.GetAttachmentType()
-	constructor, exists := attachmentTypeMap[attachmentType]
-	if !exists {
-		// Return base attachment for unknown types
-		return baseAttachment, nil
-	}
-
-	attachment := constructor()
-	if err := json.Unmarshal(data, attachment); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal attachment of type %s: %w", attachmentType, err)
-	}
-
-	return attachment, nil
-}
-
 // UpdatesParams holds parameters for getting updates
 type UpdatesParams struct {
 	Limit   int
@@ -120,6 +120,21 @@ func TestBytesToProperUpdate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "message chat created",
+			data: func(t *testing.T) []byte {
+				return []byte(`{"update_type":"message_chat_created","timestamp":1234567890,
+					"chat":{"chat_id":5,"type":"chat","status":"active","title":"Order #1","owner_id":100},
+					"message_id":"mid1","start_payload":"order-1"}`)
+			},
+			wantType: reflect.TypeOf(&schemes.MessageChatCreatedUpdate{}),
+			wantUpdate: &schemes.MessageChatCreatedUpdate{
+				Update:       schemes.Update{UpdateType: schemes.TypeMessageChatCreated, Timestamp: 1234567890},
+				Chat:         schemes.Chat{ChatId: 5, Type: schemes.ChatType("chat"), Status: schemes.ChatStatus("active"), Title: "Order #1", OwnerId: 100},
+				MessageId:    "mid1",
+				StartPayload: "order-1",
+			},
+		},
 		{
 			name: "unknown type",
 			data: func(t *testing.T) []byte { return mustMarshal(t, schemes.Update{UpdateType: "unknown"}) },
@@ -169,10 +184,7 @@ func mustMarshal(t *testing.T, v any) []byte {
 	return data
 }
 
-func TestBytesToProperAttachment(t *testing.T) {
-	api, err := New("test")
-	require.NoError(t, err)
-
+func TestUnmarshalAttachment(t *testing.T) {
 	tests := []struct {
 		name     string
 		attach   schemes.AttachmentInterface
@@ -199,7 +211,8 @@ func TestBytesToProperAttachment(t *testing.T) {
 			data, err := json.Marshal(tt.attach)
 			require.NoError(t, err)
 
-			got, err := api.bytesToProperAttachment(data)
+			got, err := schemes.UnmarshalAttachment(data)
+			require.NoError(t, err)
 			require.Equal(t, tt.wantType, reflect.TypeOf(got))
 		})
 	}
@@ -306,3 +319,78 @@ func TestGetHandler(t *testing.T) {
 		t.Error("no update received")
 	}
 }
+
+func TestMessageBodyAttachments(t *testing.T) {
+	rawMessage := `{
+		"recipient": {"chat_id": 1, "chat_type": "dialog"},
+		"timestamp": 1234567890,
+		"body": {
+			"mid": "mid1",
+			"seq": 1,
+			"text": "pick one",
+			"attachments": [
+				{"type": "image", "payload": {"photo_id": 7, "token": "photo-token", "url": "http://example.com/img.jpg"}},
+				{"type": "inline_keyboard", "payload": {"buttons": [[
+					{"type": "callback", "text": "Yes", "payload": "yes"},
+					{"type": "link", "text": "Site", "url": "https://max.ru"}
+				]]}}
+			]
+		},
+		"link": {"type": "forward", "message": {"mid": "mid0", "seq": 0, "text": "original", "attachments": [
+			{"type": "file", "payload": {"url": "http://example.com/doc.pdf", "token": "file-token"}, "filename": "doc.pdf", "size": 10}
+		]}}
+	}`
+
+	assertMessage := func(t *testing.T, message schemes.Message) {
+		t.Helper()
+
+		require.Len(t, message.Body.Attachments, 2)
+		photo, ok := message.Body.Attachments[0].(*schemes.PhotoAttachment)
+		require.True(t, ok)
+		require.Equal(t, "photo-token", photo.Payload.Token)
+
+		keyboard, ok := message.Body.Attachments[1].(*schemes.InlineKeyboardAttachment)
+		require.True(t, ok)
+		require.Len(t, keyboard.Payload.Buttons, 1)
+		require.Equal(t, &schemes.CallbackButton{
+			Button:  schemes.Button{Type: schemes.CALLBACK, Text: "Yes"},
+			Payload: "yes",
+		}, keyboard.Payload.Buttons[0][0])
+		require.Equal(t, &schemes.LinkButton{
+			Button: schemes.Button{Type: schemes.LINK, Text: "Site"},
+			Url:    "https://max.ru",
+		}, keyboard.Payload.Buttons[0][1])
+
+		require.NotNil(t, message.Link)
+		require.Len(t, message.Link.Message.Attachments, 1)
+		file, ok := message.Link.Message.Attachments[0].(*schemes.FileAttachment)
+		require.True(t, ok)
+		require.Equal(t, "doc.pdf", file.Filename)
+	}
+
+	t.Run("callback update", func(t *testing.T) {
+		api, err := New("test")
+		require.NoError(t, err)
+
+		data := []byte(`{"update_type": "message_callback", "timestamp": 1, "callback": {"callback_id": "cb1", "payload": "yes"}, "message": ` + rawMessage + `}`)
+		got, err := api.bytesToProperUpdate(data)
+		require.NoError(t, err)
+
+		upd, ok := got.(*schemes.MessageCallbackUpdate)
+		require.True(t, ok)
+		require.NotNil(t, upd.Message)
+		assertMessage(t, *upd.Message)
+	})
+
+	t.Run("get messages", func(t *testing.T) {
+		api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/messages", r.URL.Path)
+			fmt.Fprintf(w, `{"messages": [%s]}`, rawMessage)
+		})
+
+		list, err := api.Messages.GetMessages(context.Background(), 1, nil, 0, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, list.Messages, 1)
+		assertMessage(t, list.Messages[0])
+	})
+}
@@ -0,0 +1,119 @@
+package maxbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+// CallbackHandler handles pressed callback button
+type CallbackHandler func(c *CallbackContext) error
+
+// CallbackContext wraps callback update with helpers to answer it
+type CallbackContext struct {
+	Update *schemes.MessageCallbackUpdate
+
+	ctx      context.Context
+	messages *messages
+
+	mu       sync.Mutex
+	answered bool
+}
+
+// NewCallbackContext returns context for callback update
+func (a *Api) NewCallbackContext(ctx context.Context, upd *schemes.MessageCallbackUpdate) *CallbackContext {
+	return &CallbackContext{Update: upd, ctx: ctx, messages: a.Messages}
+}
+
+// HandleCallback runs handler for callback update. If handler returns without answering through CallbackContext,
+// callback is acknowledged with notification set by Messages.SetCallbackNotification, so the user never sees a spinning button
+func (a *Api) HandleCallback(ctx context.Context, upd *schemes.MessageCallbackUpdate, handler CallbackHandler) error {
+	c := a.NewCallbackContext(ctx, upd)
+	err := handler(c)
+	if notification := a.Messages.callbackNotification; notification != "" && !c.Answered() {
+		if ackErr := c.Answer(notification); ackErr != nil && !errors.Is(ackErr, ErrCallbackAnswered) {
+			return errors.Join(err, ackErr)
+		}
+	}
+	return err
+}
+
+// Context returns context of callback handling
+func (c *CallbackContext) Context() context.Context {
+	return c.ctx
+}
+
+// Payload returns payload of pressed button
+func (c *CallbackContext) Payload() string {
+	return c.Update.Callback.Payload
+}
+
+// User returns user who pressed the button
+func (c *CallbackContext) User() schemes.User {
+	return c.Update.Callback.User
+}
+
+// Chat returns identifier of chat with pressed button. Returns 0 if message had been deleted
+func (c *CallbackContext) Chat() int64 {
+	return c.Update.GetChatID()
+}
+
+// Message returns message with pressed button. Can be nil if message had been deleted
+func (c *CallbackContext) Message() *schemes.Message {
+	return c.Update.Message
+}
+
+// NewMessage returns new message addressed to the chat with pressed button
+func (c *CallbackContext) NewMessage() *Message {
+	m := NewMessage()
+	if message := c.Update.Message; message != nil {
+		m.SetChat(message.Recipient.ChatId)
+		if message.Recipient.ChatId == 0 {
+			m.SetUser(message.Recipient.UserId)
+		}
+	} else {
+		m.SetUser(c.Update.Callback.User.UserId)
+	}
+	return m
+}
+
+// Answer answers callback with one-time notification to user. Callback can be answered only once, next answers return ErrCallbackAnswered
+func (c *CallbackContext) Answer(notification string) error {
+	return c.answer(&schemes.CallbackAnswer{Notification: notification})
+}
+
+// UpdateMessage answers callback by replacing message with pressed button
+func (c *CallbackContext) UpdateMessage(m *Message) error {
+	return c.answer(&schemes.CallbackAnswer{Message: m.message})
+}
+
+// Answered reports whether callback has been answered
+func (c *CallbackContext) Answered() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answered
+}
+
+// answer sends answer unless callback is already answered. Failed answer can be sent again
+func (c *CallbackContext) answer(answer *schemes.CallbackAnswer) error {
+	c.mu.Lock()
+	if c.answered {
+		c.mu.Unlock()
+		return ErrCallbackAnswered
+	}
+	c.answered = true
+	c.mu.Unlock()
+
+	result, err := c.messages.AnswerOnCallback(c.ctx, c.Update.Callback.CallbackID, answer)
+	if err == nil && !result.Success {
+		err = errors.New(result.Message)
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.answered = false
+		c.mu.Unlock()
+	}
+	return err
+}
@@ -0,0 +1,62 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCallback(t *testing.T) {
+	var answers []schemes.CallbackAnswer
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/answers", r.URL.Path)
+		require.Equal(t, "cb1", r.URL.Query().Get("callback_id"))
+
+		var answer schemes.CallbackAnswer
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&answer))
+		answers = append(answers, answer)
+
+		json.NewEncoder(w).Encode(schemes.SimpleQueryResult{Success: true})
+	})
+
+	upd := &schemes.MessageCallbackUpdate{
+		Callback: schemes.Callback{CallbackID: "cb1", Payload: "tick", User: schemes.User{UserId: 100}},
+		Message:  &schemes.Message{Recipient: schemes.Recipient{ChatId: 42}},
+	}
+	require.Equal(t, int64(42), upd.GetChatID())
+
+	ctx := context.Background()
+	errHandler := errors.New("handler failed")
+
+	err := api.HandleCallback(ctx, upd, func(c *CallbackContext) error {
+		require.Equal(t, "tick", c.Payload())
+		require.Equal(t, int64(42), c.Chat())
+		return c.Answer("Done")
+	})
+	require.NoError(t, err)
+
+	err = api.HandleCallback(ctx, upd, func(c *CallbackContext) error {
+		return errHandler
+	})
+	require.ErrorIs(t, err, errHandler)
+
+	err = api.HandleCallback(ctx, upd, func(c *CallbackContext) error {
+		require.NoError(t, c.UpdateMessage(c.NewMessage().SetText("Updated")))
+		return c.Answer("Again")
+	})
+	require.ErrorIs(t, err, ErrCallbackAnswered)
+
+	api.Messages.SetCallbackNotification("")
+	err = api.HandleCallback(ctx, upd, func(c *CallbackContext) error { return nil })
+	require.NoError(t, err)
+
+	require.Len(t, answers, 3)
+	require.Equal(t, "Done", answers[0].Notification)
+	require.Equal(t, schemes.CallbackAnswer{Notification: "✓"}, answers[1])
+	require.Equal(t, "Updated", answers[2].Message.Text)
+}
@@ -0,0 +1,202 @@
+package maxbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// callbackSignatureSize is the number of HMAC bytes kept in signed payloads
+const callbackSignatureSize = 12
+
+// CallbackCodec encodes typed callback payloads into button payload strings and decodes them back.
+// Payload types are registered by prefix. Struct payloads are encoded as JSON arrays of their exported fields,
+// so field names do not waste the 1024 bytes limit. Encoded payload looks like `prefix:[1,"a"]`,
+// or `prefix:signature:[1,"a"]` when the codec has a secret
+type CallbackCodec struct {
+	secret []byte
+
+	mu       sync.RWMutex
+	types    map[string]reflect.Type
+	prefixes map[reflect.Type]string
+	handlers map[string]func(c *CallbackContext, payload interface{}) error
+}
+
+// NewCallbackCodec returns new callback payload codec. If secret is not empty, payloads are signed with HMAC-SHA256
+// and payloads with invalid signature are rejected on decoding
+func NewCallbackCodec(secret []byte) *CallbackCodec {
+	return &CallbackCodec{
+		secret:   secret,
+		types:    make(map[string]reflect.Type),
+		prefixes: make(map[reflect.Type]string),
+		handlers: make(map[string]func(c *CallbackContext, payload interface{}) error),
+	}
+}
+
+// Register registers type of payload under prefix
+func (c *CallbackCodec) Register(prefix string, payload interface{}) error {
+	if prefix == "" || strings.Contains(prefix, ":") {
+		return fmt.Errorf("invalid callback prefix %q", prefix)
+	}
+	t := reflect.TypeOf(payload)
+	if t == nil {
+		return fmt.Errorf("callback payload for prefix %q is nil", prefix)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.types[prefix]; exists {
+		return fmt.Errorf("callback prefix %q is already registered", prefix)
+	}
+	if _, exists := c.prefixes[t]; exists {
+		return fmt.Errorf("callback payload %s is already registered", t)
+	}
+	c.types[prefix] = t
+	c.prefixes[t] = prefix
+	return nil
+}
+
+// HandleCallbackData registers payload type T under prefix and handler called by CallbackCodec.Route for it
+func HandleCallbackData[T any](codec *CallbackCodec, prefix string, handler func(c *CallbackContext, payload T) error) error {
+	var zero T
+	if err := codec.Register(prefix, zero); err != nil {
+		return err
+	}
+
+	codec.mu.Lock()
+	defer codec.mu.Unlock()
+	codec.handlers[prefix] = func(c *CallbackContext, payload interface{}) error {
+		return handler(c, payload.(T))
+	}
+	return nil
+}
+
+// Encode encodes registered payload into button payload string
+func (c *CallbackCodec) Encode(payload interface{}) (string, error) {
+	c.mu.RLock()
+	prefix, ok := c.prefixes[reflect.TypeOf(payload)]
+	c.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: payload type %T is not registered", ErrUnknownCallback, payload)
+	}
+
+	body, err := encodeCallbackValue(reflect.ValueOf(payload))
+	if err != nil {
+		return "", &SerializationError{Op: "marshal", Type: "callback payload", Err: err}
+	}
+
+	result := prefix + ":" + body
+	if len(c.secret) > 0 {
+		result = prefix + ":" + c.sign(prefix, body) + ":" + body
+	}
+	if len(result) > MaxCallbackPayloadLength {
+		return "", fmt.Errorf("encoded callback payload length %d exceeds %d bytes", len(result), MaxCallbackPayloadLength)
+	}
+	return result, nil
+}
+
+// MustEncode is like Encode but panics on error. Use it with KeyboardRow.AddCallback
+func (c *CallbackCodec) MustEncode(payload interface{}) string {
+	result, err := c.Encode(payload)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// Decode decodes button payload string into registered payload. Returns prefix and value of registered type
+func (c *CallbackCodec) Decode(data string) (string, interface{}, error) {
+	prefix, rest, ok := strings.Cut(data, ":")
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %q", ErrUnknownCallback, data)
+	}
+
+	c.mu.RLock()
+	t, ok := c.types[prefix]
+	c.mu.RUnlock()
+	if !ok {
+		return "", nil, fmt.Errorf("%w: prefix %q", ErrUnknownCallback, prefix)
+	}
+
+	body := rest
+	if len(c.secret) > 0 {
+		signature, signed, ok := strings.Cut(rest, ":")
+		if !ok || !hmac.Equal([]byte(signature), []byte(c.sign(prefix, signed))) {
+			return "", nil, ErrInvalidSignature
+		}
+		body = signed
+	}
+
+	value := reflect.New(t).Elem()
+	if err := decodeCallbackValue(body, value); err != nil {
+		return "", nil, &SerializationError{Op: "unmarshal", Type: "callback payload", Err: err}
+	}
+	return prefix, value.Interface(), nil
+}
+
+// Route decodes payload of pressed button and calls handler registered by HandleCallbackData.
+// Use it as CallbackHandler: api.HandleCallback(ctx, upd, codec.Route)
+func (c *CallbackCodec) Route(cc *CallbackContext) error {
+	prefix, payload, err := c.Decode(cc.Payload())
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	handler, ok := c.handlers[prefix]
+	c.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: no handler for prefix %q", ErrUnknownCallback, prefix)
+	}
+	return handler(cc, payload)
+}
+
+func (c *CallbackCodec) sign(prefix string, body string) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(prefix + ":" + body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:callbackSignatureSize])
+}
+
+// encodeCallbackValue encodes structs as JSON arrays of exported fields and other values as JSON
+func encodeCallbackValue(v reflect.Value) (string, error) {
+	if v.Kind() != reflect.Struct {
+		data, err := json.Marshal(v.Interface())
+		return string(data), err
+	}
+
+	fields := make([]interface{}, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			fields = append(fields, v.Field(i).Interface())
+		}
+	}
+	data, err := json.Marshal(fields)
+	return string(data), err
+}
+
+func decodeCallbackValue(body string, v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return json.Unmarshal([]byte(body), v.Addr().Interface())
+	}
+
+	var fields []json.RawMessage
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return err
+	}
+	n := 0
+	for i := 0; i < v.NumField() && n < len(fields); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if err := json.Unmarshal(fields[n], v.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("field %s: %w", v.Type().Field(i).Name, err)
+		}
+		n++
+	}
+	return nil
+}
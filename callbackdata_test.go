@@ -0,0 +1,74 @@
+package maxbot
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+type testPage struct {
+	Query string
+	Page  int
+}
+
+func TestCallbackCodec(t *testing.T) {
+	t.Run("encode and decode", func(t *testing.T) {
+		codec := NewCallbackCodec(nil)
+		require.NoError(t, codec.Register("p", testPage{}))
+		require.NoError(t, codec.Register("id", int64(0)))
+		require.Error(t, codec.Register("p", ""))
+
+		data, err := codec.Encode(testPage{Query: "cats", Page: 2})
+		require.NoError(t, err)
+		require.Equal(t, `p:["cats",2]`, data)
+
+		prefix, payload, err := codec.Decode(data)
+		require.NoError(t, err)
+		require.Equal(t, "p", prefix)
+		require.Equal(t, testPage{Query: "cats", Page: 2}, payload)
+
+		_, payload, err = codec.Decode(codec.MustEncode(int64(7)))
+		require.NoError(t, err)
+		require.Equal(t, int64(7), payload)
+
+		_, _, err = codec.Decode("unknown:1")
+		require.ErrorIs(t, err, ErrUnknownCallback)
+		_, err = codec.Encode("not registered")
+		require.ErrorIs(t, err, ErrUnknownCallback)
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		codec := NewCallbackCodec([]byte("secret"))
+		require.NoError(t, codec.Register("p", testPage{}))
+
+		data := codec.MustEncode(testPage{Query: "cats", Page: 2})
+		_, payload, err := codec.Decode(data)
+		require.NoError(t, err)
+		require.Equal(t, testPage{Query: "cats", Page: 2}, payload)
+
+		_, _, err = codec.Decode(data[:len(data)-2] + "3]")
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		_, _, err = codec.Decode(`p:["cats",3]`)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("route", func(t *testing.T) {
+		api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success": true}`))
+		})
+
+		codec := NewCallbackCodec(nil)
+		var got testPage
+		require.NoError(t, HandleCallbackData(codec, "p", func(c *CallbackContext, payload testPage) error {
+			got = payload
+			return nil
+		}))
+
+		upd := &schemes.MessageCallbackUpdate{Callback: schemes.Callback{Payload: codec.MustEncode(testPage{Page: 3})}}
+		require.NoError(t, api.HandleCallback(context.Background(), upd, codec.Route))
+		require.Equal(t, testPage{Page: 3}, got)
+	})
+}
@@ -9,8 +9,6 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-
-	"github.com/rectid/max-bot-api-client-go/schemes"
 )
 
 var (
@@ -20,6 +18,13 @@ var (
 	}
 )
 
+// errorResponse is body of API response with error
+type errorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	ErrorText string `json:"error"`
+}
+
 type client struct {
 	key        string
 	version    string
@@ -114,14 +119,19 @@ func (cl *client) requestReader(ctx context.Context, method, path string, query
 			}
 		}()
 
-		apiErr := &schemes.Error{}
+		apiErr := &errorResponse{}
 		if decodeErr := json.NewDecoder(resp.Body).Decode(apiErr); decodeErr != nil {
 			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
 		}
 
+		message := apiErr.ErrorText
+		if message == "" {
+			message = apiErr.Message
+		}
 		return nil, &APIError{
-			Code:    resp.StatusCode,
-			Message: apiErr.Error(),
+			Code:      resp.StatusCode,
+			Message:   message,
+			ErrorCode: apiErr.Code,
 		}
 	}
 
@@ -0,0 +1,114 @@
+package maxbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+// DownloadInfo describes downloaded attachment
+type DownloadInfo struct {
+	Filename    string // Name of file from attachment or response of server
+	ContentType string // MIME type from response of server
+	Size        int64  // Number of bytes written
+}
+
+// Download writes content of received photo, video, audio, file or sticker attachment to w.
+// Content is requested with configured HTTP client and limits of URL uploads.
+// Returns ErrIncompleteDownload if number of received bytes differs from size of file reported by API or server
+func (a *uploads) Download(ctx context.Context, attachment schemes.AttachmentInterface, w io.Writer) (*DownloadInfo, error) {
+	url, filename, size := downloadSource(attachment)
+	if url == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNotDownloadable, attachment.GetAttachmentType())
+	}
+
+	resp, err := a.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	opts := remoteFileOptions(resp, UploadOptions{Filename: filename})
+	info := &DownloadInfo{Filename: opts.Filename, ContentType: opts.ContentType}
+	if info.Size, err = io.Copy(w, resp.Body); err != nil {
+		return info, err
+	}
+	if size <= 0 {
+		size = resp.ContentLength
+	}
+	if size >= 0 && info.Size != size {
+		return info, fmt.Errorf("%w: received %d of %d bytes", ErrIncompleteDownload, info.Size, size)
+	}
+	return info, nil
+}
+
+// DownloadToFile saves content of received attachment to file. If path is existing directory,
+// file is saved into it under name of attachment. File is written completely or not created at all
+func (a *uploads) DownloadToFile(ctx context.Context, attachment schemes.AttachmentInterface, path string) (*DownloadInfo, error) {
+	dir, name := filepath.Dir(path), filepath.Base(path)
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		dir, name = path, ""
+	}
+
+	tmp, err := createDownloadFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	info, err := a.Download(ctx, attachment, tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && name == "" {
+		name = filepath.Base(info.Filename)
+		if strings.HasPrefix(name, ".") || name == string(filepath.Separator) {
+			name = "file"
+		}
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(dir, name))
+	}
+	if err != nil {
+		return info, errors.Join(err, os.Remove(tmp.Name()))
+	}
+	return info, nil
+}
+
+// createDownloadFile creates hidden temporary file in dir. Unlike os.CreateTemp it uses permissions of os.Create,
+// so renamed file has the same permissions as other created files
+func createDownloadFile(dir string) (*os.File, error) {
+	for attempt := 0; ; attempt++ {
+		name := filepath.Join(dir, ".download-"+strconv.FormatUint(rand.Uint64(), 36))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o666)
+		if errors.Is(err, fs.ErrExist) && attempt < 100 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// downloadSource returns url, file name and size of attachment content. Name and size are known only for files
+func downloadSource(attachment schemes.AttachmentInterface) (string, string, int64) {
+	switch a := attachment.(type) {
+	case *schemes.PhotoAttachment:
+		return a.Payload.Url, "", 0
+	case *schemes.VideoAttachment:
+		return a.Payload.Url, "", 0
+	case *schemes.AudioAttachment:
+		return a.Payload.Url, "", 0
+	case *schemes.FileAttachment:
+		return a.Payload.Url, a.Filename, a.Size
+	case *schemes.StickerAttachment:
+		return a.Payload.Url, "", 0
+	default:
+		return "", "", 0
+	}
+}
@@ -0,0 +1,80 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		if name := r.URL.Query().Get("name"); name != "" {
+			w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+		}
+		_, _ = w.Write([]byte("%PDF-1.4"))
+	}))
+	t.Cleanup(server.Close)
+
+	api, err := New("test")
+	require.NoError(t, err)
+
+	file := &schemes.FileAttachment{
+		Attachment: schemes.Attachment{Type: schemes.AttachmentFile},
+		Payload:    schemes.FileAttachmentPayload{Url: server.URL + "/download?id=1"},
+		Filename:   "report.pdf",
+		Size:       8,
+	}
+	buf := &bytes.Buffer{}
+	info, err := api.Uploads.Download(context.Background(), file, buf)
+	require.NoError(t, err)
+	require.Equal(t, &DownloadInfo{Filename: "report.pdf", ContentType: "application/pdf", Size: 8}, info)
+	require.Equal(t, "%PDF-1.4", buf.String())
+
+	dir := t.TempDir()
+	_, err = api.Uploads.DownloadToFile(context.Background(), file, dir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "report.pdf"))
+	require.NoError(t, err)
+	require.Equal(t, "%PDF-1.4", string(data))
+
+	file.Size = 100
+	_, err = api.Uploads.DownloadToFile(context.Background(), file, filepath.Join(dir, "broken.pdf"))
+	require.ErrorIs(t, err, ErrIncompleteDownload)
+	_, err = os.Stat(filepath.Join(dir, "broken.pdf"))
+	require.True(t, os.IsNotExist(err))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary file is removed")
+
+	created, err := os.Create(filepath.Join(t.TempDir(), "created"))
+	require.NoError(t, err)
+	require.NoError(t, created.Close())
+	createdStat, err := os.Stat(created.Name())
+	require.NoError(t, err)
+	downloadedStat, err := os.Stat(filepath.Join(dir, "report.pdf"))
+	require.NoError(t, err)
+	require.Equal(t, createdStat.Mode(), downloadedStat.Mode(), "downloaded file has permissions of os.Create")
+
+	for name, saved := range map[string]string{"..": "file", ".bashrc": "file", "../../evil": "evil"} {
+		unsafeDir := t.TempDir()
+		photo := &schemes.PhotoAttachment{Payload: schemes.PhotoAttachmentPayload{Url: server.URL + "/photo?name=" + url.QueryEscape(name)}}
+		info, err := api.Uploads.DownloadToFile(context.Background(), photo, unsafeDir)
+		require.NoError(t, err)
+		require.Equal(t, name, info.Filename)
+		_, err = os.Stat(filepath.Join(unsafeDir, saved))
+		require.NoError(t, err, name)
+	}
+
+	_, err = api.Uploads.Download(context.Background(), &schemes.LocationAttachment{}, buf)
+	require.ErrorIs(t, err, ErrNotDownloadable)
+}
@@ -3,17 +3,37 @@ package maxbot
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
 )
 
 var (
 	ErrEmptyToken = errors.New("bot token is empty")
 	ErrInvalidURL = errors.New("invalid API URL")
+	ErrNoMessage  = errors.New("message is not available")
+
+	ErrUnknownCallback  = errors.New("unknown callback payload")
+	ErrInvalidSignature = errors.New("invalid callback payload signature")
+	ErrCallbackAnswered = errors.New("callback is already answered")
+
+	ErrRemoteFileStatus   = errors.New("remote file request failed")
+	ErrRemoteFileTooLarge = errors.New("remote file is too large")
+	ErrPrivateAddress     = errors.New("remote address is private")
+	ErrNotDownloadable    = errors.New("attachment has no downloadable content")
+	ErrIncompleteDownload = errors.New("downloaded size does not match file size")
+
+	ErrImageTooLarge = errors.New("image has too many pixels")
 )
 
+// ErrCodeAttachmentNotReady is API error code returned when uploaded attachment is still being processed
+const ErrCodeAttachmentNotReady = "attachment.not.ready"
+
 type APIError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	Details   string `json:"details,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"` // Error code from API response, like "attachment.not.ready"
 }
 
 func (e *APIError) Error() string {
@@ -30,6 +50,12 @@ func (e *APIError) Is(target error) bool {
 	return false
 }
 
+// IsAttachmentNotReady reports whether err is returned because uploaded attachment is not processed yet
+func IsAttachmentNotReady(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == ErrCodeAttachmentNotReady
+}
+
 type NetworkError struct {
 	Op  string
 	Err error
@@ -58,6 +84,21 @@ func (e *TimeoutError) Timeout() bool {
 	return true
 }
 
+// UploadError is returned when upload server rejected file or its response has no tokens
+type UploadError struct {
+	Type       schemes.UploadType
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *UploadError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("upload error for %s: HTTP %d: %s: %s", e.Type, e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("upload error for %s: HTTP %d: %s", e.Type, e.StatusCode, e.Message)
+}
+
 type SerializationError struct {
 	Op   string
 	Type string
@@ -71,3 +112,26 @@ func (e *SerializationError) Error() string {
 func (e *SerializationError) Unwrap() error {
 	return e.Err
 }
+
+// Violation describes one violated constraint of message
+type Violation struct {
+	Field   string // Path to the invalid field, e.g. "attachments[0].buttons[1][2].text"
+	Message string
+}
+
+// ValidationError lists every constraint violated by message before it was sent or by built keyboard
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, fmt.Sprintf("%s: %s", v.Field, v.Message))
+	}
+	return fmt.Sprintf("validation error: %s", strings.Join(messages, "; "))
+}
+
+func (e *ValidationError) add(field string, format string, args ...interface{}) {
+	e.Violations = append(e.Violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+}
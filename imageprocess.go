@@ -0,0 +1,312 @@
+package maxbot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// defaultJPEGQuality is used to re-encode JPEG photos when quality is not set
+const defaultJPEGQuality = 90
+
+// DefaultMaxImagePixels limits number of pixels of photos decoded by image processor
+const DefaultMaxImagePixels = 50_000_000
+
+// ImageOptions configures preprocessing of photos before upload
+type ImageOptions struct {
+	MaxDimension  int  // Photos with larger width or height are scaled down to fit. Zero keeps size
+	JPEGQuality   int  // If set, photos are re-encoded to JPEG with quality from 1 to 100
+	StripMetadata bool // Removes EXIF, XMP and IPTC metadata, like location, from JPEG photos. EXIF orientation is applied to pixels
+	MaxPixels     int  // Photos which have to be decoded and have more pixels fail with ErrImageTooLarge. Zero uses DefaultMaxImagePixels, negative disables limit
+}
+
+// ImageProcessor transforms photo before upload. Returns new content and options with updated filename and content type
+type ImageProcessor func(reader io.Reader, opts UploadOptions) (io.Reader, UploadOptions, error)
+
+// SetImageProcessor sets processor applied to every photo before upload. Nil processor disables preprocessing
+func (a *uploads) SetImageProcessor(processor ImageProcessor) {
+	a.imageProcessor = processor
+}
+
+// NewImageProcessor returns processor which resizes, re-encodes and strips metadata of JPEG and PNG photos
+// using only standard library. Photos in other formats and GIF animations are uploaded unchanged
+func NewImageProcessor(options ImageOptions) ImageProcessor {
+	return func(reader io.Reader, opts UploadOptions) (io.Reader, UploadOptions, error) {
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, opts, err
+		}
+		result, format, err := processImage(data, options)
+		if err != nil || format == "" {
+			return bytes.NewReader(data), opts, err
+		}
+
+		if format == "jpeg" && opts.ContentType != "image/jpeg" {
+			opts.ContentType = "image/jpeg"
+			if opts.Filename != "" {
+				opts.Filename = strings.TrimSuffix(opts.Filename, filepath.Ext(opts.Filename)) + ".jpg"
+			}
+		}
+		return bytes.NewReader(result), opts, nil
+	}
+}
+
+// processImage returns processed image and its format or empty format if image is left unchanged
+func processImage(data []byte, options ImageOptions) ([]byte, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, "", nil
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	resize := options.MaxDimension > 0 && max(config.Width, config.Height) > options.MaxDimension
+	reencode := resize || options.JPEGQuality > 0 || (options.StripMetadata && orientation != 1)
+	if !reencode {
+		if options.StripMetadata && format == "jpeg" {
+			if stripped, ok := stripJPEGMetadata(data); ok {
+				return stripped, format, nil
+			}
+		}
+		return nil, "", nil
+	}
+
+	maxPixels := options.MaxPixels
+	if maxPixels == 0 {
+		maxPixels = DefaultMaxImagePixels
+	}
+	if maxPixels > 0 && int64(config.Width)*int64(config.Height) > int64(maxPixels) {
+		return nil, "", fmt.Errorf("%w: %dx%d exceeds %d pixels", ErrImageTooLarge, config.Width, config.Height, maxPixels)
+	}
+
+	var img image.Image
+	if img, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+		return nil, "", err
+	}
+	// Resizing to fit square does not depend on orientation, so image is resized first to orient smaller copy
+	if resize {
+		img = resizeToFit(img, options.MaxDimension)
+	}
+	if orientation >= 2 && orientation <= 8 {
+		img = orient(img, orientation)
+	}
+
+	out := &bytes.Buffer{}
+	if options.JPEGQuality > 0 || format == "jpeg" {
+		quality := options.JPEGQuality
+		if quality <= 0 {
+			quality = defaultJPEGQuality
+		}
+		if o, ok := img.(interface{ Opaque() bool }); !ok || !o.Opaque() {
+			img = flatten(img)
+		}
+		err = jpeg.Encode(out, img, &jpeg.Options{Quality: min(quality, 100)})
+		return out.Bytes(), "jpeg", err
+	}
+	err = png.Encode(out, img)
+	return out.Bytes(), "png", err
+}
+
+// flatten returns image drawn over white background, because JPEG has no transparency.
+// Image of type *image.RGBA is created by processImage, so it is changed in place
+func flatten(src image.Image) *image.RGBA {
+	dst, ok := src.(*image.RGBA)
+	if !ok {
+		b := src.Bounds()
+		dst = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	}
+	// Colors are premultiplied by alpha, so white background adds uncovered part to every channel
+	for i := 0; i+3 < len(dst.Pix); i += 4 {
+		uncovered := 255 - dst.Pix[i+3]
+		dst.Pix[i] += uncovered
+		dst.Pix[i+1] += uncovered
+		dst.Pix[i+2] += uncovered
+		dst.Pix[i+3] = 255
+	}
+	return dst
+}
+
+// resizeToFit scales image down to fit maxDimension averaging source pixels covered by every result pixel.
+// Source rows are converted by bands, so only small part of large image is copied at once
+func resizeToFit(src image.Image, maxDimension int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dw, dh := maxDimension, maxDimension
+	if sw >= sh {
+		dh = max(1, (sh*maxDimension+sw/2)/sw)
+	} else {
+		dw = max(1, (sw*maxDimension+sh/2)/sh)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	band := image.NewRGBA(image.Rect(0, 0, sw, (sh+dh-1)/dh+1))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		draw.Draw(band, image.Rect(0, 0, sw, y1-y0), src, image.Pt(b.Min.X, b.Min.Y+y0), draw.Src)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+			var sum [4]int
+			for by := 0; by < y1-y0; by++ {
+				row := band.Pix[by*band.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// orient transforms image according to EXIF orientation, so it is displayed correctly without metadata.
+// Source is converted row by row, so only result is fully allocated
+func orient(src image.Image, orientation int) *image.RGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	row := image.NewRGBA(image.Rect(0, 0, w, 1))
+	for sy := 0; sy < h; sy++ {
+		draw.Draw(row, row.Bounds(), src, image.Pt(b.Min.X, b.Min.Y+sy), draw.Src)
+		for sx := 0; sx < w; sx++ {
+			var x, y int
+			switch orientation {
+			case 2: // flip horizontal
+				x, y = w-1-sx, sy
+			case 3: // rotate 180
+				x, y = w-1-sx, h-1-sy
+			case 4: // flip vertical
+				x, y = sx, h-1-sy
+			case 5: // transpose
+				x, y = sy, sx
+			case 6: // rotate 90 clockwise
+				x, y = h-1-sy, sx
+			case 7: // transverse
+				x, y = h-1-sy, w-1-sx
+			case 8: // rotate 90 counterclockwise
+				x, y = sy, w-1-sx
+			default:
+				x, y = sx, sy
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], row.Pix[sx*4:sx*4+4])
+		}
+	}
+	return dst
+}
+
+// jpegSegments calls fn for every marker segment of JPEG header until start of scan.
+// Returns offset of start of scan or -1 if data is not a valid JPEG
+func jpegSegments(data []byte, fn func(marker byte, segment []byte, payload []byte)) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return -1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return -1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // fill byte
+			i++
+			continue
+		case marker == 0xDA: // start of scan
+			return i
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // markers without payload
+			fn(marker, data[i:i+2], nil)
+			i += 2
+			continue
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return -1
+		}
+		fn(marker, data[i:end], data[i+4:end])
+		i = end
+	}
+	return -1
+}
+
+// stripJPEGMetadata removes APP1 segments with EXIF and XMP metadata and APP13 segments with IPTC metadata
+// without re-encoding image
+func stripJPEGMetadata(data []byte) ([]byte, bool) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:min(2, len(data))])
+	sos := jpegSegments(data, func(marker byte, segment []byte, _ []byte) {
+		if marker != 0xE1 && marker != 0xED {
+			out.Write(segment)
+		}
+	})
+	if sos < 0 {
+		return nil, false
+	}
+	out.Write(data[sos:])
+	return out.Bytes(), true
+}
+
+// jpegOrientation returns orientation from EXIF metadata of JPEG or 1 if it is not set
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, _ []byte, payload []byte) {
+		if marker != 0xE1 || !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return
+		}
+		if o := exifOrientation(payload[6:]); o != 0 {
+			orientation = o
+		}
+	})
+	return orientation
+}
+
+// exifOrientation reads orientation tag from first IFD of TIFF structure. Returns 0 if tag is not found
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,209 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testJPEGWithOrientation returns JPEG with EXIF orientation tag inserted after SOI marker
+func testJPEGWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, jpeg.Encode(buf, img, nil))
+	data := buf.Bytes()
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = append(tiff, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(payload)+2))
+	app1 = append(app1, payload...)
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func testImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+func TestImageProcessor(t *testing.T) {
+	pngData := &bytes.Buffer{}
+	require.NoError(t, png.Encode(pngData, testImage(200, 100)))
+
+	t.Run("resize", func(t *testing.T) {
+		reader, opts, err := NewImageProcessor(ImageOptions{MaxDimension: 50})(bytes.NewReader(pngData.Bytes()), UploadOptions{Filename: "big.png", ContentType: "image/png"})
+		require.NoError(t, err)
+		require.Equal(t, UploadOptions{Filename: "big.png", ContentType: "image/png"}, opts)
+
+		img, format, err := image.Decode(reader)
+		require.NoError(t, err)
+		require.Equal(t, "png", format)
+		require.Equal(t, image.Rect(0, 0, 50, 25), img.Bounds())
+	})
+
+	t.Run("jpeg quality", func(t *testing.T) {
+		reader, opts, err := NewImageProcessor(ImageOptions{JPEGQuality: 80})(bytes.NewReader(pngData.Bytes()), UploadOptions{Filename: "big.png", ContentType: "image/png"})
+		require.NoError(t, err)
+		require.Equal(t, UploadOptions{Filename: "big.jpg", ContentType: "image/jpeg"}, opts)
+
+		config, format, err := image.DecodeConfig(reader)
+		require.NoError(t, err)
+		require.Equal(t, "jpeg", format)
+		require.Equal(t, 200, config.Width)
+	})
+
+	t.Run("small image is unchanged", func(t *testing.T) {
+		reader, _, err := NewImageProcessor(ImageOptions{MaxDimension: 500})(bytes.NewReader(pngData.Bytes()), UploadOptions{})
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, pngData.Bytes(), data)
+	})
+
+	t.Run("unknown format is unchanged", func(t *testing.T) {
+		reader, _, err := NewImageProcessor(ImageOptions{MaxDimension: 10, JPEGQuality: 50})(strings.NewReader("not an image"), UploadOptions{})
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, "not an image", string(data))
+	})
+
+	t.Run("strip metadata", func(t *testing.T) {
+		data := testJPEGWithOrientation(t, testImage(20, 10), 1)
+		require.True(t, bytes.Contains(data, []byte("Exif")))
+
+		reader, _, err := NewImageProcessor(ImageOptions{StripMetadata: true})(bytes.NewReader(data), UploadOptions{})
+		require.NoError(t, err)
+		stripped, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(stripped, []byte("Exif")))
+		require.Less(t, len(stripped), len(data))
+
+		_, err = jpeg.Decode(bytes.NewReader(stripped))
+		require.NoError(t, err)
+	})
+
+	t.Run("strip iptc", func(t *testing.T) {
+		data := testJPEGWithOrientation(t, testImage(20, 10), 1)
+		app13 := append([]byte{0xFF, 0xED, 0x00, 0x10}, []byte("Photoshop 3.0\x00")...)
+		data = append(append(append([]byte{}, data[:2]...), app13...), data[2:]...)
+
+		reader, _, err := NewImageProcessor(ImageOptions{StripMetadata: true})(bytes.NewReader(data), UploadOptions{})
+		require.NoError(t, err)
+		stripped, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(stripped, []byte("Photoshop")))
+		require.False(t, bytes.Contains(stripped, []byte("Exif")))
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		_, _, err := NewImageProcessor(ImageOptions{MaxDimension: 50, MaxPixels: 100})(bytes.NewReader(pngData.Bytes()), UploadOptions{})
+		require.ErrorIs(t, err, ErrImageTooLarge)
+
+		_, _, err = NewImageProcessor(ImageOptions{MaxDimension: 50, MaxPixels: -1})(bytes.NewReader(pngData.Bytes()), UploadOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("resize and orient", func(t *testing.T) {
+		data := testJPEGWithOrientation(t, testImage(200, 100), 6)
+		reader, _, err := NewImageProcessor(ImageOptions{MaxDimension: 50})(bytes.NewReader(data), UploadOptions{})
+		require.NoError(t, err)
+		config, err := jpeg.DecodeConfig(reader)
+		require.NoError(t, err)
+		require.Equal(t, 25, config.Width)
+		require.Equal(t, 50, config.Height)
+	})
+
+	t.Run("apply orientation", func(t *testing.T) {
+		data := testJPEGWithOrientation(t, testImage(20, 10), 6)
+		require.Equal(t, 6, jpegOrientation(data))
+
+		reader, _, err := NewImageProcessor(ImageOptions{StripMetadata: true})(bytes.NewReader(data), UploadOptions{})
+		require.NoError(t, err)
+		processed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.False(t, bytes.Contains(processed, []byte("Exif")))
+
+		config, err := jpeg.DecodeConfig(bytes.NewReader(processed))
+		require.NoError(t, err)
+		require.Equal(t, 10, config.Width)
+		require.Equal(t, 20, config.Height)
+	})
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	src.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+	// rotate 90 clockwise puts left pixel on top
+	img := orient(src, 6)
+	require.Equal(t, image.Rect(0, 0, 1, 2), img.Bounds())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, img.RGBAAt(0, 0))
+	require.Equal(t, color.RGBA{B: 255, A: 255}, img.RGBAAt(0, 1))
+
+	img = orient(src, 8)
+	require.Equal(t, color.RGBA{B: 255, A: 255}, img.RGBAAt(0, 0))
+
+	img = orient(src, 2)
+	require.Equal(t, color.RGBA{B: 255, A: 255}, img.RGBAAt(0, 0))
+
+	transparent := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	transparent.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 0})
+	require.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, flatten(transparent).RGBAAt(0, 0))
+}
+
+func TestUploadProcessedPhoto(t *testing.T) {
+	var filename, contentType string
+	var config image.Config
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err == nil {
+			part, err := multipart.NewReader(r.Body, params["boundary"]).NextPart()
+			if err == nil {
+				filename, contentType = part.FileName(), part.Header.Get("Content-Type")
+				config, _, _ = image.DecodeConfig(part)
+			}
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"token":"photo-token"}`))
+	})
+	api.Uploads.SetImageProcessor(NewImageProcessor(ImageOptions{MaxDimension: 100, JPEGQuality: 85}))
+
+	pngData := &bytes.Buffer{}
+	require.NoError(t, png.Encode(pngData, testImage(400, 200)))
+
+	result, err := api.Uploads.UploadFile(context.Background(), bytes.NewReader(pngData.Bytes()), UploadOptions{Filename: "photo.png"})
+	require.NoError(t, err)
+	require.Equal(t, "photo.jpg", result.Filename)
+	require.Equal(t, "image/jpeg", result.ContentType)
+	require.Equal(t, "photo-token", result.Photo.Token)
+
+	require.Equal(t, "photo.jpg", filename)
+	require.Equal(t, "image/jpeg", contentType)
+	require.Equal(t, 100, config.Width)
+	require.Equal(t, 50, config.Height)
+}
@@ -7,6 +7,43 @@ type Keyboard struct {
 	rows []*KeyboardRow
 }
 
+// KeyboardFromSchema returns keyboard builder filled with buttons of received keyboard.
+// Buttons are copied, so changing the builder does not change the received message
+func KeyboardFromSchema(keyboard schemes.Keyboard) *Keyboard {
+	k := &Keyboard{rows: make([]*KeyboardRow, 0, len(keyboard.Buttons))}
+	for _, buttons := range keyboard.Buttons {
+		row := k.AddRow()
+		for _, b := range buttons {
+			row.AddButton(buttonValue(b))
+		}
+	}
+	return k
+}
+
+// buttonValue returns copy of decoded button as value like ones created by KeyboardRow
+func buttonValue(button schemes.ButtonInterface) schemes.ButtonInterface {
+	switch b := button.(type) {
+	case *schemes.Button:
+		return *b
+	case *schemes.CallbackButton:
+		return *b
+	case *schemes.LinkButton:
+		return *b
+	case *schemes.RequestContactButton:
+		return *b
+	case *schemes.RequestGeoLocationButton:
+		return *b
+	case *schemes.ChatButton:
+		return *b
+	case *schemes.MessageButton:
+		return *b
+	case *schemes.OpenAppButton:
+		return *b
+	default:
+		return button
+	}
+}
+
 // AddRow adds row to inline keyboard
 func (k *Keyboard) AddRow() *KeyboardRow {
 	kr := &KeyboardRow{}
@@ -14,8 +51,88 @@ func (k *Keyboard) AddRow() *KeyboardRow {
 	return kr
 }
 
-// Build returns result keyboard
-func (k *Keyboard) Build() schemes.Keyboard {
+// Build returns inline keyboard or *ValidationError if it exceeds API limits
+func (k *Keyboard) Build() (schemes.Keyboard, error) {
+	keyboard := k.build()
+	verr := &ValidationError{}
+	validateKeyboard(verr, "keyboard", keyboard.Buttons)
+	if len(verr.Violations) > 0 {
+		return keyboard, verr
+	}
+	return keyboard, nil
+}
+
+// Rows returns rows of keyboard
+func (k *Keyboard) Rows() []*KeyboardRow {
+	return k.rows
+}
+
+// Map replaces every button of keyboard with result of fn. Use it to change buttons of received keyboard
+func (k *Keyboard) Map(fn func(button schemes.ButtonInterface) schemes.ButtonInterface) *Keyboard {
+	for _, r := range k.rows {
+		for i, b := range r.cols {
+			r.cols[i] = fn(b)
+		}
+	}
+	return k
+}
+
+// Grid adds buttons split into rows of cols buttons
+func (k *Keyboard) Grid(buttons []schemes.ButtonInterface, cols int) *Keyboard {
+	if cols <= 0 {
+		cols = MaxButtonsPerRow
+	}
+	for i := 0; i < len(buttons); i += cols {
+		row := k.AddRow()
+		for _, b := range buttons[i:min(i+cols, len(buttons))] {
+			row.AddButton(b)
+		}
+	}
+	return k
+}
+
+// Paginate adds buttons of page (counted from zero) with perPage buttons, one button per row,
+// and navigation row with buttons to previous and next pages. navPayload returns callback payload for page number
+func (k *Keyboard) Paginate(items []schemes.ButtonInterface, page int, perPage int, navPayload func(page int) string) *Keyboard {
+	if perPage <= 0 {
+		perPage = len(items)
+	}
+	pages := 1
+	if perPage > 0 {
+		pages = (len(items) + perPage - 1) / perPage
+	}
+	page = min(max(page, 0), max(pages-1, 0))
+
+	from := min(page*perPage, len(items))
+	k.Grid(items[from:min(from+perPage, len(items))], 1)
+
+	if pages > 1 {
+		nav := k.AddRow()
+		if page > 0 {
+			nav.AddCallback("«", schemes.DEFAULT, navPayload(page-1))
+		}
+		if page < pages-1 {
+			nav.AddCallback("»", schemes.DEFAULT, navPayload(page+1))
+		}
+	}
+	return k
+}
+
+// Confirm adds row with "Yes" and "No" callback buttons. Use ConfirmWith to set labels
+func (k *Keyboard) Confirm(yesPayload string, noPayload string) *Keyboard {
+	return k.ConfirmWith("Yes", yesPayload, "No", noPayload)
+}
+
+// ConfirmWith adds row with positive and negative callback buttons with given labels
+func (k *Keyboard) ConfirmWith(yesText string, yesPayload string, noText string, noPayload string) *Keyboard {
+	k.AddRow().
+		AddCallback(yesText, schemes.POSITIVE, yesPayload).
+		AddCallback(noText, schemes.NEGATIVE, noPayload)
+	return k
+}
+
+// build returns inline keyboard unchecked, Message.AddKeyboard leaves checks to Message.Validate
+func (k *Keyboard) build() schemes.Keyboard {
 	buttons := make([][]schemes.ButtonInterface, 0, len(k.rows))
 	for _, r := range k.rows {
 		buttons = append(buttons, r.Build())
@@ -36,7 +153,8 @@ func (k *KeyboardRow) Build() []schemes.ButtonInterface {
 // AddLink button
 func (k *KeyboardRow) AddLink(text string, intent schemes.Intent, url string) *KeyboardRow {
 	b := schemes.LinkButton{
-		Url: url,
+		Url:    url,
+		Intent: intent,
 		Button: schemes.Button{
 			Text: text,
 			Type: schemes.LINK,
@@ -84,3 +202,56 @@ func (k *KeyboardRow) AddGeolocation(text string, quick bool) *KeyboardRow {
 	k.cols = append(k.cols, b)
 	return k
 }
+
+// AddChat button. Creates new chat with given title as soon as the first user clicked on it
+func (k *KeyboardRow) AddChat(text string, title string, description string, startPayload string) *KeyboardRow {
+	b := schemes.ChatButton{
+		ChatTitle:       title,
+		ChatDescription: description,
+		StartPayload:    startPayload,
+		Button: schemes.Button{
+			Text: text,
+			Type: schemes.CHAT_BUTTON,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
+
+// AddMessage button. Sends its text as a message from user
+func (k *KeyboardRow) AddMessage(text string) *KeyboardRow {
+	b := schemes.MessageButton{
+		Button: schemes.Button{
+			Text: text,
+			Type: schemes.MESSAGE,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
+
+// AddOpenApp button. Opens mini app of bot given by username or link
+func (k *KeyboardRow) AddOpenApp(text string, webApp string, payload string) *KeyboardRow {
+	b := schemes.OpenAppButton{
+		WebApp:  webApp,
+		Payload: payload,
+		Button: schemes.Button{
+			Text: text,
+			Type: schemes.OPEN_APP,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
+
+// SetButton replaces button at index i of row
+func (k *KeyboardRow) SetButton(i int, button schemes.ButtonInterface) *KeyboardRow {
+	k.cols[i] = button
+	return k
+}
+
+// AddButton adds any button
+func (k *KeyboardRow) AddButton(button schemes.ButtonInterface) *KeyboardRow {
+	k.cols = append(k.cols, button)
+	return k
+}
@@ -46,6 +46,16 @@ func (m *Message) SetText(text string) *Message {
 	return m
 }
 
+// SetRichText sets text composed by TextBuilder with its format or markup entities
+func (m *Message) SetRichText(text *TextBuilder) *Message {
+	m.message.Text = text.String()
+	m.message.Format = string(text.Format())
+	if text.Format() == "" {
+		m.message.Markups = text.Markups()
+	}
+	return m
+}
+
 func (m *Message) SetFormat(format string) *Message {
 	m.message.Format = format
 	return m
@@ -73,19 +83,32 @@ func (m *Message) Reply(text string, reply schemes.Message) *Message {
 	return m
 }
 
+// Forward makes message a forward of message with given id. Text may be left empty
+func (m *Message) Forward(id string) *Message {
+	m.message.Link = &schemes.NewMessageLink{Type: schemes.FORWARD, Mid: id}
+	return m
+}
+
 func (m *Message) AddMarkUp(user int64, from int, len int) *Message {
 	m.message.Markups = append(m.message.Markups, schemes.MarkUp{UserId: user, From: from, Length: len, Type: schemes.MarkupUser})
 	return m
 }
 
 func (m *Message) AddKeyboard(keyboard *Keyboard) *Message {
-	m.message.Attachments = append(m.message.Attachments, schemes.NewInlineKeyboardAttachmentRequest(keyboard.Build()))
+	m.message.Attachments = append(m.message.Attachments, schemes.NewInlineKeyboardAttachmentRequest(keyboard.build()))
+	return m
+}
+
+// AddReplyKeyboard adds reply keyboard shown instead of user's keyboard
+func (m *Message) AddReplyKeyboard(keyboard *ReplyKeyboard) *Message {
+	m.message.Attachments = append(m.message.Attachments, keyboard.build())
 	return m
 }
 
 func (m *Message) AddPhoto(photo *schemes.PhotoTokens) *Message {
 	m.message.Attachments = append(m.message.Attachments, schemes.NewPhotoAttachmentRequest(schemes.PhotoAttachmentRequestPayload{
 		Photos: photo.Photos,
+		Token:  photo.Token,
 	}))
 	return m
 }
@@ -105,6 +128,31 @@ func (m *Message) AddFile(file *schemes.UploadedInfo) *Message {
 	return m
 }
 
+// AddUpload attaches file uploaded by Uploads.UploadFile according to its upload type
+func (m *Message) AddUpload(upload *UploadResult) *Message {
+	switch upload.Type {
+	case schemes.PHOTO:
+		return m.AddPhoto(upload.Photo)
+	case schemes.VIDEO:
+		return m.AddVideo(upload.Info)
+	case schemes.AUDIO:
+		return m.AddAudio(upload.Info)
+	default:
+		return m.AddFile(upload.Info)
+	}
+}
+
+// AddMediaGroup attaches several uploaded files to one message. Nil uploads are skipped. Photos and videos can be combined,
+// while audio and file must be the only attachment, which is checked by Validate before sending
+func (m *Message) AddMediaGroup(uploads ...*UploadResult) *Message {
+	for _, upload := range uploads {
+		if upload != nil {
+			m.AddUpload(upload)
+		}
+	}
+	return m
+}
+
 func (m *Message) AddLocation(lat float64, lon float64) *Message {
 	m.message.Attachments = append(m.message.Attachments, schemes.NewLocationAttachmentRequest(lat, lon))
 	return m
@@ -126,3 +174,21 @@ func (m *Message) AddSticker(code string) *Message {
 	}))
 	return m
 }
+
+// AddAttachment attaches received attachment to message again. Media is reused by its token without uploading
+func (m *Message) AddAttachment(attachment schemes.AttachmentInterface) *Message {
+	if request, ok := schemes.NewAttachmentRequestFrom(attachment); ok {
+		m.message.Attachments = append(m.message.Attachments, request)
+	}
+	return m
+}
+
+// AddAttachmentsFrom attaches all attachments of received message body
+func (m *Message) AddAttachmentsFrom(body schemes.MessageBody) *Message {
+	for _, a := range body.Attachments {
+		if attachment, ok := a.(schemes.AttachmentInterface); ok {
+			m.AddAttachment(attachment)
+		}
+	}
+	return m
+}
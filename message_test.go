@@ -0,0 +1,180 @@
+package maxbot
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageAddAttachmentsFrom(t *testing.T) {
+	var body schemes.MessageBody
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"mid": "mid1",
+		"attachments": [
+			{"type": "image", "payload": {"photo_id": 1, "token": "photo-token", "url": "http://example.com/1.jpg"}},
+			{"type": "video", "payload": {"url": "http://example.com/1.mp4", "token": "video-token"}},
+			{"type": "location", "latitude": 55.75, "longitude": 37.61},
+			{"type": "unknown"}
+		]
+	}`), &body))
+
+	require.Len(t, body.Photos(), 1)
+	require.Len(t, body.Videos(), 1)
+	require.Empty(t, body.Files())
+	require.Nil(t, body.Contact())
+	require.NotNil(t, body.Location())
+	require.Equal(t, 55.75, body.Location().Latitude)
+
+	m := NewMessage().AddAttachmentsFrom(body)
+	require.Equal(t, []interface{}{
+		schemes.NewPhotoAttachmentRequest(schemes.PhotoAttachmentRequestPayload{Token: "photo-token"}),
+		schemes.NewVideoAttachmentRequest(schemes.UploadedInfo{Token: "video-token"}),
+		schemes.NewLocationAttachmentRequest(55.75, 37.61),
+	}, m.message.Attachments)
+}
+
+func TestKeyboardButtonsRoundTrip(t *testing.T) {
+	keyboard := &Keyboard{}
+	keyboard.AddRow().
+		AddLink("Site", schemes.POSITIVE, "https://max.ru").
+		AddChat("Discuss", "Order #1", "Talk about order", "order-1").
+		AddMessage("Hello").
+		AddOpenApp("App", "mybot", "start")
+
+	built, err := keyboard.Build()
+	require.NoError(t, err)
+	data, err := json.Marshal(schemes.NewInlineKeyboardAttachmentRequest(built))
+	require.NoError(t, err)
+
+	attachment, err := schemes.UnmarshalAttachment(data)
+	require.NoError(t, err)
+	require.Equal(t, [][]schemes.ButtonInterface{{
+		&schemes.LinkButton{Button: schemes.Button{Type: schemes.LINK, Text: "Site"}, Url: "https://max.ru", Intent: schemes.POSITIVE},
+		&schemes.ChatButton{Button: schemes.Button{Type: schemes.CHAT_BUTTON, Text: "Discuss"}, ChatTitle: "Order #1", ChatDescription: "Talk about order", StartPayload: "order-1"},
+		&schemes.MessageButton{Button: schemes.Button{Type: schemes.MESSAGE, Text: "Hello"}},
+		&schemes.OpenAppButton{Button: schemes.Button{Type: schemes.OPEN_APP, Text: "App"}, WebApp: "mybot", Payload: "start"},
+	}}, attachment.(*schemes.InlineKeyboardAttachment).Payload.Buttons)
+}
+
+func TestReplyKeyboardRoundTrip(t *testing.T) {
+	keyboard := &ReplyKeyboard{}
+	keyboard.SetDirectUser(42).AddRow().
+		AddMessage("Yes", schemes.POSITIVE, "yes").
+		AddGeolocation("Where am I", true).
+		AddContact("Share contact")
+
+	m := NewMessage().SetChat(1).SetText("Choose").AddReplyKeyboard(keyboard)
+	require.NoError(t, m.Validate())
+
+	request, err := keyboard.Build()
+	require.NoError(t, err)
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"reply_keyboard","direct_user_id":42,"buttons":[[
+		{"type":"message","text":"Yes","payload":"yes","intent":"positive"},
+		{"type":"user_geo_location","text":"Where am I","quick":true},
+		{"type":"user_contact","text":"Share contact"}
+	]]}`, string(data))
+
+	attachment, err := schemes.UnmarshalAttachment(data)
+	require.NoError(t, err)
+	require.Equal(t, [][]schemes.ReplyButtonInterface{{
+		&schemes.SendMessageButton{ReplyButton: schemes.ReplyButton{Type: schemes.MESSAGE, Text: "Yes", Payload: "yes"}, Intent: schemes.POSITIVE},
+		&schemes.SendGeoLocationButton{ReplyButton: schemes.ReplyButton{Type: schemes.USER_GEOLOCATION, Text: "Where am I"}, Quick: true},
+		&schemes.SendContactButton{ReplyButton: schemes.ReplyButton{Type: schemes.USER_CONTACT, Text: "Share contact"}},
+	}}, attachment.(*schemes.ReplyKeyboardAttachment).Buttons)
+
+	var body schemes.MessageBody
+	require.NoError(t, json.Unmarshal([]byte(`{"mid":"m1","text":"Yes","attachments":[{"type":"data","data":"yes"}]}`), &body))
+	require.Equal(t, "yes", body.Data())
+}
+
+func TestKeyboardLayouts(t *testing.T) {
+	items := make([]schemes.ButtonInterface, 0, 5)
+	for i := 1; i <= 5; i++ {
+		items = append(items, schemes.CallbackButton{Button: schemes.Button{Type: schemes.CALLBACK, Text: fmt.Sprint(i)}, Payload: fmt.Sprint(i)})
+	}
+	rowLengths := func(k *Keyboard) []int {
+		built, err := k.Build()
+		require.NoError(t, err)
+		lengths := make([]int, 0, len(built.Buttons))
+		for _, row := range built.Buttons {
+			lengths = append(lengths, len(row))
+		}
+		return lengths
+	}
+
+	require.Equal(t, []int{2, 2, 1}, rowLengths((&Keyboard{}).Grid(items, 2)))
+	require.Equal(t, []int{2, 2}, rowLengths((&Keyboard{}).Confirm("yes", "no").Confirm("yes", "no")))
+
+	page := func(n int) string { return fmt.Sprintf("page:%d", n) }
+	first, err := (&Keyboard{}).Paginate(items, 0, 2, page).Build()
+	require.NoError(t, err)
+	require.Len(t, first.Buttons, 3)
+	require.Equal(t, []schemes.ButtonInterface{
+		schemes.CallbackButton{Button: schemes.Button{Type: schemes.CALLBACK, Text: "»"}, Payload: "page:1", Intent: schemes.DEFAULT},
+	}, first.Buttons[2])
+
+	middle, err := (&Keyboard{}).Paginate(items, 1, 2, page).Build()
+	require.NoError(t, err)
+	require.Equal(t, "3", middle.Buttons[0][0].GetText())
+	require.Len(t, middle.Buttons[2], 2)
+
+	last, err := (&Keyboard{}).Paginate(items, 10, 2, page).Build()
+	require.NoError(t, err)
+	require.Equal(t, "5", last.Buttons[0][0].GetText())
+	require.Equal(t, "«", last.Buttons[1][0].GetText())
+
+	tooWide := make([]schemes.ButtonInterface, 0, 8)
+	for i := 0; i < 8; i++ {
+		tooWide = append(tooWide, items[0])
+	}
+	_, err = (&Keyboard{}).Grid(tooWide, 8).Build()
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "keyboard.buttons[0]", verr.Violations[0].Field)
+
+	confirm, err := (&Keyboard{}).ConfirmWith("Да", "yes", "Нет", "no").Build()
+	require.NoError(t, err)
+	require.Equal(t, "Да", confirm.Buttons[0][0].GetText())
+	require.Equal(t, "Нет", confirm.Buttons[0][1].GetText())
+
+	reply := &ReplyKeyboard{}
+	row := reply.AddRow()
+	for i := 0; i < 8; i++ {
+		row.AddContact("Contact")
+	}
+	_, err = reply.Build()
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, "keyboard.buttons[0]", verr.Violations[0].Field)
+}
+
+func TestKeyboardFromSchema(t *testing.T) {
+	var body schemes.MessageBody
+	require.NoError(t, json.Unmarshal([]byte(`{"mid":"m1","text":"Options","attachments":[{"type":"inline_keyboard","payload":{"buttons":[
+		[{"type":"callback","text":"[ ] Cats","payload":"cats"},{"type":"callback","text":"[ ] Dogs","payload":"dogs"}],
+		[{"type":"link","text":"Site","url":"https://max.ru"}]
+	]}}]}`), &body))
+	received := body.Keyboard().Payload
+
+	keyboard := KeyboardFromSchema(received).Map(func(button schemes.ButtonInterface) schemes.ButtonInterface {
+		if b, ok := button.(schemes.CallbackButton); ok && b.Payload == "cats" {
+			b.Text = "[x] Cats"
+			return b
+		}
+		return button
+	})
+	built, err := keyboard.Build()
+	require.NoError(t, err)
+	require.Equal(t, [][]schemes.ButtonInterface{
+		{
+			schemes.CallbackButton{Button: schemes.Button{Type: schemes.CALLBACK, Text: "[x] Cats"}, Payload: "cats"},
+			schemes.CallbackButton{Button: schemes.Button{Type: schemes.CALLBACK, Text: "[ ] Dogs"}, Payload: "dogs"},
+		},
+		{schemes.LinkButton{Button: schemes.Button{Type: schemes.LINK, Text: "Site"}, Url: "https://max.ru"}},
+	}, built.Buttons)
+	require.Equal(t, "[ ] Cats", received.Buttons[0][0].GetText())
+}
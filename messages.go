@@ -9,16 +9,47 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rectid/max-bot-api-client-go/schemes"
 )
 
+// Defaults of waiting for uploaded attachments to be processed
+const (
+	defaultAttachmentReadyTimeout = 30 * time.Second
+	defaultAttachmentRetryDelay   = 500 * time.Millisecond
+	maxAttachmentRetryDelay       = 5 * time.Second
+)
+
+// defaultCallbackNotification is sent by HandleCallback when handler does not answer callback
+const defaultCallbackNotification = "✓"
+
 type messages struct {
-	client *client
+	client               *client
+	readyTimeout         time.Duration
+	retryDelay           time.Duration
+	callbackNotification string
 }
 
 func newMessages(client *client) *messages {
-	return &messages{client: client}
+	return &messages{
+		client:               client,
+		readyTimeout:         defaultAttachmentReadyTimeout,
+		retryDelay:           defaultAttachmentRetryDelay,
+		callbackNotification: defaultCallbackNotification,
+	}
+}
+
+// SetAttachmentReadyTimeout sets how long sending of message is retried while its uploaded attachments are processed.
+// Default is 30 seconds. Zero disables retries, so attachment.not.ready error is returned immediately
+func (a *messages) SetAttachmentReadyTimeout(timeout time.Duration) {
+	a.readyTimeout = timeout
+}
+
+// SetCallbackNotification sets notification which HandleCallback sends when handler does not answer callback.
+// Default is "✓". Empty notification disables automatic answer
+func (a *messages) SetCallbackNotification(notification string) {
+	a.callbackNotification = notification
 }
 
 // GetMessages returns messages in chat: result page and marker referencing to the next page. Messages traversed in reverse direction so the latest message in chat will be first in result array. Therefore if you use from and to parameters, to must be less than from
@@ -83,6 +114,89 @@ func (a *messages) DeleteMessage(ctx context.Context, messageID string) (*scheme
 	return result, json.NewDecoder(body).Decode(result)
 }
 
+// EditText replaces text of received message. Attachments and keyboard of message are kept
+func (a *messages) EditText(ctx context.Context, source schemes.MessageSource, text string) error {
+	message := source.GetMessage()
+	if message == nil {
+		return ErrNoMessage
+	}
+	return a.editKeepingAttachments(ctx, message, &schemes.NewMessageBody{Text: text}, false, nil)
+}
+
+// EditKeyboard replaces keyboard of received message. Text and other attachments of message are kept
+func (a *messages) EditKeyboard(ctx context.Context, source schemes.MessageSource, keyboard *Keyboard) error {
+	message := source.GetMessage()
+	if message == nil {
+		return ErrNoMessage
+	}
+	built, err := keyboard.Build()
+	if err != nil {
+		return err
+	}
+	return a.editKeepingAttachments(ctx, message, nil, true, schemes.NewInlineKeyboardAttachmentRequest(built))
+}
+
+// RemoveKeyboard removes keyboard from received message. Text and other attachments of message are kept
+func (a *messages) RemoveKeyboard(ctx context.Context, source schemes.MessageSource) error {
+	message := source.GetMessage()
+	if message == nil {
+		return ErrNoMessage
+	}
+	return a.editKeepingAttachments(ctx, message, nil, true, nil)
+}
+
+// DeleteMessageFrom deletes received message
+func (a *messages) DeleteMessageFrom(ctx context.Context, source schemes.MessageSource) (*schemes.SimpleQueryResult, error) {
+	message := source.GetMessage()
+	if message == nil {
+		return new(schemes.SimpleQueryResult), ErrNoMessage
+	}
+	return a.DeleteMessage(ctx, message.Body.Mid)
+}
+
+// editMessageBody is NewMessageBody which sends attachments even if there are none, so the last attachment can be removed.
+// Markup of received message is sent as is, so markup unknown to this package is kept
+type editMessageBody struct {
+	*schemes.NewMessageBody
+	Attachments []interface{}     `json:"attachments"`
+	RawMarkup   []json.RawMessage `json:"markup,omitempty"`
+}
+
+// editKeepingAttachments edits message reusing tokens of its attachments. If body is nil, text and markup of message are kept.
+// If replaceKeyboard is true, existing keyboards are dropped and keyboard is attached instead when not nil
+func (a *messages) editKeepingAttachments(ctx context.Context, message *schemes.Message, body *schemes.NewMessageBody, replaceKeyboard bool, keyboard interface{}) error {
+	edit := &editMessageBody{NewMessageBody: body}
+	if body == nil {
+		edit.NewMessageBody = &schemes.NewMessageBody{Text: message.Body.Text}
+		edit.RawMarkup = message.Body.RawMarkup
+	}
+	attachments := make([]interface{}, 0, len(message.Body.Attachments)+1)
+	for _, item := range message.Body.Attachments {
+		attachment, ok := item.(schemes.AttachmentInterface)
+		if !ok {
+			continue
+		}
+		request, ok := schemes.NewAttachmentRequestFrom(attachment)
+		if !ok || (replaceKeyboard && isKeyboardRequest(request)) {
+			continue
+		}
+		attachments = append(attachments, request)
+	}
+	if keyboard != nil {
+		attachments = append(attachments, keyboard)
+	}
+
+	edit.Attachments = attachments
+	s, err := a.editMessage(ctx, message.Body.Mid, edit)
+	if err != nil {
+		return err
+	}
+	if !s.Success {
+		return errors.New(s.Message)
+	}
+	return nil
+}
+
 // AnswerOnCallback should be called to send an answer after a user has clicked the button. The answer may be an updated message or/and a one-time user notification.
 func (a *messages) AnswerOnCallback(ctx context.Context, callbackID string, callback *schemes.CallbackAnswer) (*schemes.SimpleQueryResult, error) {
 	result := new(schemes.SimpleQueryResult)
@@ -107,22 +221,127 @@ func (a *messages) NewKeyboardBuilder() *Keyboard {
 	}
 }
 
+// NewReplyKeyboardBuilder returns new reply keyboard builder helper
+func (a *messages) NewReplyKeyboardBuilder() *ReplyKeyboard {
+	return &ReplyKeyboard{
+		rows: make([]*ReplyKeyboardRow, 0),
+	}
+}
+
 // Send sends a message to a chat. As a result for this method new message identifier returns.
+// Message is validated before sending, see Message.Validate
 func (a *messages) Send(ctx context.Context, m *Message) (string, error) {
+	if err := m.Validate(); err != nil {
+		return "", err
+	}
 	return a.sendMessage(ctx, m.vip, m.reset, m.chatID, m.userID, m.message)
 }
 
 // SendMessageResult sends a message to a chat and returns the message result.
 func (a *messages) SendMessageResult(ctx context.Context, m *Message) (schemes.Message, error) {
+	return a.send(ctx, m)
+}
+
+// SendLong sends a message with text of any length. Text longer than MaxTextLength is split by SplitText and sent as several messages in order.
+// Attachments are sent with the first part and keyboards with the last one. As a result identifiers of all sent messages return
+func (a *messages) SendLong(ctx context.Context, m *Message) ([]string, error) {
+	parts := SplitText(m.message.Text, schemes.TextFormat(m.message.Format), m.message.Markups, MaxTextLength)
+	if len(parts) <= 1 {
+		result, err := a.send(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+		return []string{result.Body.Mid}, nil
+	}
+
+	var attachments, keyboards []interface{}
+	for _, attachment := range m.message.Attachments {
+		if isKeyboardRequest(attachment) {
+			keyboards = append(keyboards, attachment)
+		} else {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	mids := make([]string, 0, len(parts))
+	for i, p := range parts {
+		body := *m.message
+		body.Text = p.Text
+		body.Markups = p.Markups
+		body.Attachments = []interface{}{}
+		if i == 0 {
+			body.Attachments = append(body.Attachments, attachments...)
+		} else {
+			body.Link = nil
+		}
+		if i == len(parts)-1 {
+			body.Attachments = append(body.Attachments, keyboards...)
+		}
+
+		part := *m
+		part.message = &body
+		result, err := a.send(ctx, &part)
+		if err != nil {
+			return mids, err
+		}
+		mids = append(mids, result.Body.Mid)
+	}
+
+	return mids, nil
+}
+
+// isKeyboardRequest reports whether attachment request is a keyboard
+func isKeyboardRequest(attachment interface{}) bool {
+	switch attachment.(type) {
+	case *schemes.InlineKeyboardAttachmentRequest, *schemes.ReplyKeyboardAttachmentRequest:
+		return true
+	default:
+		return false
+	}
+}
+
+// ForwardMessage forwards message with given id to a chat or a user. As a result for this method new message identifier returns.
+func (a *messages) ForwardMessage(ctx context.Context, messageID string, chatID int64, userID int64) (string, error) {
+	result, err := a.send(ctx, NewMessage().SetChat(chatID).SetUser(userID).Forward(messageID))
+	if err != nil {
+		return "", err
+	}
+	return result.Body.Mid, nil
+}
+
+// send sends a message and returns created message. Unlike Send it returns nil error on success
+func (a *messages) send(ctx context.Context, m *Message) (schemes.Message, error) {
+	if err := m.Validate(); err != nil {
+		return schemes.Message{}, err
+	}
 	_, err := a.sendMessage(ctx, m.vip, m.reset, m.chatID, m.userID, m.message)
-	switch message := err.(type) {
-	case *schemes.Error:
-		return message.Message, nil
+	var result *schemes.Error
+	if errors.As(err, &result) && result.Code == "" {
+		return result.Message, nil
 	}
 	return schemes.Message{}, err
 }
 
+// sendMessage sends message retrying with backoff while its attachments are not ready
 func (a *messages) sendMessage(ctx context.Context, vip bool, reset bool, chatID int64, userID int64, message *schemes.NewMessageBody) (string, error) {
+	deadline := time.Now().Add(a.readyTimeout)
+	delay := a.retryDelay
+	for {
+		mid, err := a.sendMessageOnce(ctx, vip, reset, chatID, userID, message)
+		if !IsAttachmentNotReady(err) || time.Now().Add(delay).After(deadline) {
+			return mid, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return mid, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxAttachmentRetryDelay)
+	}
+}
+
+func (a *messages) sendMessageOnce(ctx context.Context, vip bool, reset bool, chatID int64, userID int64, message *schemes.NewMessageBody) (string, error) {
 	result := new(schemes.Error)
 	values := url.Values{}
 	if chatID != 0 {
@@ -160,7 +379,7 @@ func (a *messages) sendMessage(ctx context.Context, vip bool, reset bool, chatID
 	return "", result
 }
 
-func (a *messages) editMessage(ctx context.Context, messageID string, message *schemes.NewMessageBody) (*schemes.SimpleQueryResult, error) {
+func (a *messages) editMessage(ctx context.Context, messageID string, message interface{}) (*schemes.SimpleQueryResult, error) {
 	result := new(schemes.SimpleQueryResult)
 	values := url.Values{}
 	values.Set("message_id", messageID)
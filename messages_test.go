@@ -0,0 +1,192 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestApi(t *testing.T, handler http.HandlerFunc) *Api {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	api, err := New("test")
+	require.NoError(t, err)
+	api.client.baseURL, err = url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return api
+}
+
+func TestForwardMessage(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/messages", r.URL.Path)
+		require.Equal(t, "42", r.URL.Query().Get("chat_id"))
+
+		body := new(schemes.NewMessageBody)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(body))
+		require.Equal(t, &schemes.NewMessageLink{Type: schemes.FORWARD, Mid: "mid1"}, body.Link)
+
+		json.NewEncoder(w).Encode(schemes.SendMessageResult{Message: schemes.Message{
+			Recipient: schemes.Recipient{ChatId: 42},
+			Body:      schemes.MessageBody{Mid: "mid2"},
+			Link: &schemes.LinkedMessage{
+				Type:   schemes.FORWARD,
+				Sender: schemes.User{UserId: 100, Name: "Alice"},
+				ChatId: 1,
+			},
+		}})
+	})
+
+	mid, err := api.Messages.ForwardMessage(context.Background(), "mid1", 42, 0)
+	require.NoError(t, err)
+	require.Equal(t, "mid2", mid)
+}
+
+func TestSendMessageResult(t *testing.T) {
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chat_id") == "1" {
+			json.NewEncoder(w).Encode(schemes.SendMessageResult{Message: schemes.Message{Body: schemes.MessageBody{Mid: "mid1"}}})
+			return
+		}
+		w.Write([]byte(`{"code":"chat.denied","error":"chat denied"}`))
+	})
+
+	message, err := api.Messages.SendMessageResult(context.Background(), NewMessage().SetChat(1).SetText("hi"))
+	require.NoError(t, err)
+	require.Equal(t, "mid1", message.Body.Mid)
+
+	_, err = api.Messages.SendMessageResult(context.Background(), NewMessage().SetChat(2).SetText("hi"))
+	var apiErr *schemes.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "chat.denied", apiErr.Code)
+}
+
+func TestSendLong(t *testing.T) {
+	var bodies []*schemes.NewMessageBody
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		body := new(schemes.NewMessageBody)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(body))
+		bodies = append(bodies, body)
+
+		json.NewEncoder(w).Encode(schemes.SendMessageResult{Message: schemes.Message{
+			Body: schemes.MessageBody{Mid: "mid" + strconv.Itoa(len(bodies))},
+		}})
+	})
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("OK", schemes.POSITIVE, "ok")
+	text := strings.Repeat("word ", MaxTextLength/5) + "tail"
+	m := NewMessage().SetChat(1).SetText(text).AddLocation(1, 2).AddKeyboard(keyboard)
+
+	mids, err := api.Messages.SendLong(context.Background(), m)
+	require.NoError(t, err)
+	require.Equal(t, []string{"mid1", "mid2"}, mids)
+	require.Len(t, bodies, 2)
+	require.Equal(t, strings.TrimSpace(strings.Repeat("word ", MaxTextLength/5)), bodies[0].Text)
+	require.Equal(t, "tail", bodies[1].Text)
+	require.Len(t, bodies[0].Attachments, 1)
+	require.Equal(t, "location", bodies[0].Attachments[0].(map[string]interface{})["type"])
+	require.Len(t, bodies[1].Attachments, 1)
+	require.Equal(t, "inline_keyboard", bodies[1].Attachments[0].(map[string]interface{})["type"])
+}
+
+func TestEditKeepingAttachments(t *testing.T) {
+	var bodies []map[string]interface{}
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "mid1", r.URL.Query().Get("message_id"))
+
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		json.NewEncoder(w).Encode(schemes.SimpleQueryResult{Success: true})
+	})
+
+	update := new(schemes.MessageCallbackUpdate)
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"update_type": "message_callback",
+		"callback": {"callback_id": "cb1", "payload": "tick"},
+		"message": {"recipient": {"chat_id": 1}, "body": {"mid": "mid1", "text": "Hi Bob", "markup": [{"type": "strong", "from": 3, "length": 3}, {"type": "user_mention", "from": 3, "length": 3, "user_link": "@bob"}, {"type": "future", "from": 0, "length": 2}],
+			"attachments": [
+				{"type": "image", "payload": {"photo_id": 1, "token": "photo-token", "url": "http://example.com/1.jpg"}},
+				{"type": "inline_keyboard", "payload": {"buttons": [[{"type": "callback", "text": "Tick", "payload": "tick"}]]}}
+			]}}
+	}`), update))
+
+	keyboard := api.Messages.NewKeyboardBuilder()
+	keyboard.AddRow().AddCallback("Ticked", schemes.POSITIVE, "untick")
+
+	ctx := context.Background()
+	require.NoError(t, api.Messages.EditKeyboard(ctx, update, keyboard))
+	require.NoError(t, api.Messages.RemoveKeyboard(ctx, update))
+	require.NoError(t, api.Messages.EditText(ctx, update, "Bye"))
+	require.ErrorIs(t, api.Messages.EditText(ctx, &schemes.MessageCallbackUpdate{}, "Bye"), ErrNoMessage)
+
+	photo := map[string]interface{}{"type": "image", "payload": map[string]interface{}{"token": "photo-token"}}
+	require.Len(t, bodies, 3)
+	require.Equal(t, "Hi Bob", bodies[0]["text"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"from": 3.0, "length": 3.0, "type": "strong"},
+		map[string]interface{}{"from": 3.0, "length": 3.0, "type": "user_mention", "user_link": "@bob"},
+		map[string]interface{}{"from": 0.0, "length": 2.0, "type": "future"},
+	}, bodies[0]["markup"])
+	require.Equal(t, bodies[0]["markup"], bodies[1]["markup"])
+	require.Equal(t, photo, bodies[0]["attachments"].([]interface{})[0])
+	require.Len(t, bodies[0]["attachments"], 2)
+	require.Contains(t, mustMarshalString(t, bodies[0]["attachments"]), `"payload":"untick"`)
+	require.Equal(t, []interface{}{photo}, bodies[1]["attachments"])
+	require.Equal(t, "Bye", bodies[2]["text"])
+	require.Len(t, bodies[2]["attachments"], 2)
+}
+
+func mustMarshalString(t *testing.T, v any) string {
+	t.Helper()
+
+	return string(mustMarshal(t, v))
+}
+
+func TestSendRetriesAttachmentNotReady(t *testing.T) {
+	attempts := 0
+	api := newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"attachment.not.ready","message":"Key: errors.process.attachment.file.not.processed"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(schemes.SendMessageResult{Message: schemes.Message{Body: schemes.MessageBody{Mid: "mid1"}}})
+	})
+	api.Messages.retryDelay = time.Millisecond
+
+	message := NewMessage().SetChat(1).AddFile(&schemes.UploadedInfo{Token: "file-token"})
+	mid, err := api.Messages.send(context.Background(), message)
+	require.NoError(t, err)
+	require.Equal(t, "mid1", mid.Body.Mid)
+	require.Equal(t, 3, attempts)
+
+	attempts = -100
+	api.Messages.SetAttachmentReadyTimeout(0)
+	_, err = api.Messages.send(context.Background(), message)
+	require.True(t, IsAttachmentNotReady(err))
+	require.Equal(t, -99, attempts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	api.Messages.SetAttachmentReadyTimeout(time.Minute)
+	api.Messages.retryDelay = time.Second
+	_, err = api.Messages.send(ctx, message)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
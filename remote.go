@@ -0,0 +1,172 @@
+package maxbot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxRemoteSize is default limit of files uploaded from URL and downloaded
+const DefaultMaxRemoteSize = 4 << 30
+
+// SetMaxRemoteSize limits size of files uploaded from URL and downloaded. Default is DefaultMaxRemoteSize, zero means no limit
+func (a *uploads) SetMaxRemoteSize(size int64) {
+	a.maxRemoteSize = size
+}
+
+// SetRemoteTimeout limits duration of requesting remote file including reading its content.
+// Zero uses timeout of configured HTTP client, negative value disables timeout
+func (a *uploads) SetRemoteTimeout(timeout time.Duration) {
+	a.remoteTimeout = timeout
+}
+
+// SetBlockPrivateNetwork forbids uploads from URLs resolving to loopback, private, link-local, multicast
+// and other non-public addresses. Addresses are checked on connection, so redirects and DNS rebinding are checked too.
+// Proxy of configured transport is not used while blocking, because only address of proxy could be checked.
+// Guarded copy of transport is created once here, so connections are reused between requests
+func (a *uploads) SetBlockPrivateNetwork(block bool) {
+	a.publicTransport = nil
+	if block {
+		a.publicTransport = publicOnlyTransport(a.client.httpClient.Transport)
+	}
+}
+
+// fetch requests remote file with configured HTTP client. Returns error for non-2xx responses and files larger than max remote size.
+// Body of response reports its size, so it is uploaded with known Content-Length
+func (a *uploads) fetch(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := *a.client.httpClient
+	switch {
+	case a.remoteTimeout > 0:
+		client.Timeout = a.remoteTimeout
+	case a.remoteTimeout < 0:
+		client.Timeout = 0
+	}
+	if a.publicTransport != nil {
+		client.Transport = a.publicTransport
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &NetworkError{Op: "GET " + req.URL.Redacted(), Err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: GET %s: HTTP %d", ErrRemoteFileStatus, req.URL.Redacted(), resp.StatusCode)
+	}
+	if a.maxRemoteSize > 0 && resp.ContentLength > a.maxRemoteSize {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %d bytes exceed %d", ErrRemoteFileTooLarge, resp.ContentLength, a.maxRemoteSize)
+	}
+	resp.Body = &remoteBody{body: resp.Body, size: resp.ContentLength, limit: a.maxRemoteSize}
+	return resp, nil
+}
+
+// remoteBody is body of remote file which fails when more than limit bytes are read
+type remoteBody struct {
+	body  io.ReadCloser
+	size  int64
+	limit int64
+	read  int64
+}
+
+func (b *remoteBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.limit > 0 && b.read > b.limit {
+		return n, fmt.Errorf("%w: more than %d bytes", ErrRemoteFileTooLarge, b.limit)
+	}
+	return n, err
+}
+
+// Len returns number of bytes left or -1 if size is unknown
+func (b *remoteBody) Len() int {
+	if b.size < 0 {
+		return -1
+	}
+	return int(b.size - b.read)
+}
+
+func (b *remoteBody) Close() error {
+	return b.body.Close()
+}
+
+// nonPublicPrefixes are special-purpose ranges which are not covered by netip.Addr methods
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // this network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved and broadcast
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+var (
+	nat64Prefix     = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFourPrefix = netip.MustParsePrefix("2002::/16")
+)
+
+// publicOnlyTransport returns copy of transport which refuses connections to non-public addresses.
+// Proxy is disabled, otherwise address of proxy instead of target would be checked
+func publicOnlyTransport(transport http.RoundTripper) http.RoundTripper {
+	base, ok := transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	result := base.Clone()
+	result.Proxy = nil
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if addr := addrPort.Addr().Unmap(); !isPublicAddr(addr) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addr)
+			}
+			return nil
+		},
+	}
+	result.DialContext = dialer.DialContext
+	return result
+}
+
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if embedded, ok := embeddedIPv4(addr); ok {
+		return isPublicAddr(embedded)
+	}
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// embeddedIPv4 returns IPv4 address translated by NAT64 or 6to4 address
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFourPrefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,135 @@
+package maxbot
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadFromURL(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.png":
+			w.WriteHeader(http.StatusNotFound)
+		case "/stream.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+		default:
+			w.Header().Set("Content-Type", "image/png")
+			w.Header().Set("Content-Disposition", `attachment; filename="logo.png"`)
+			_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+		}
+	}))
+	t.Cleanup(remote.Close)
+
+	var contentLength int64
+	var filename, contentType string
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		file, header, err := r.FormFile("data")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.Copy(io.Discard, file)
+		contentLength, filename, contentType = r.ContentLength, header.Filename, header.Header.Get("Content-Type")
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+
+	photo, err := api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/image")
+	require.NoError(t, err)
+	require.Equal(t, "photo-token", photo.Photos["p1"].Token)
+	require.Positive(t, contentLength)
+	require.Equal(t, "logo.png", filename)
+	require.Equal(t, "image/png", contentType)
+
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/missing.png")
+	require.ErrorIs(t, err, ErrRemoteFileStatus)
+
+	require.Equal(t, int64(DefaultMaxRemoteSize), api.Uploads.maxRemoteSize)
+	api.Uploads.SetMaxRemoteSize(50)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/image")
+	require.ErrorIs(t, err, ErrRemoteFileTooLarge)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/stream.png")
+	require.ErrorIs(t, err, ErrRemoteFileTooLarge)
+
+	api.Uploads.SetMaxRemoteSize(0)
+	api.Uploads.SetBlockPrivateNetwork(true)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/image")
+	require.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"8.8.8.8":            true,
+		"2a00:1450::1":       true,
+		"64:ff9b::808:808":   true,
+		"127.0.0.1":          false,
+		"10.0.0.1":           false,
+		"169.254.169.254":    false,
+		"100.64.0.1":         false,
+		"0.1.2.3":            false,
+		"224.0.0.1":          false,
+		"255.255.255.255":    false,
+		"::ffff:10.0.0.1":    false,
+		"64:ff9b::a9fe:a9fe": false,
+		"2002:a00:1::":       false,
+		"ff02::1":            false,
+		"fd00::1":            false,
+		"64:ff9b:1::a00:1":   false,
+		"2002:808:808::1":    true,
+	} {
+		require.Equal(t, want, isPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestFetchProxyAndTimeout(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	t.Cleanup(proxy.Close)
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+	api.client.httpClient.Transport = &http.Transport{Proxy: http.ProxyURL(proxyURL)}
+	api.Uploads.SetBlockPrivateNetwork(true)
+
+	transport := api.Uploads.publicTransport
+	require.Nil(t, transport.(*http.Transport).Proxy)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), "http://10.0.0.1/image.png")
+	require.ErrorIs(t, err, ErrPrivateAddress)
+	require.False(t, proxied)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), "http://10.0.0.1/image.png")
+	require.ErrorIs(t, err, ErrPrivateAddress)
+	require.Same(t, transport, api.Uploads.publicTransport, "guarded transport is reused")
+
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(stalled.Close)
+
+	api.client.httpClient.Transport = nil
+	api.Uploads.SetBlockPrivateNetwork(false)
+	api.Uploads.SetRemoteTimeout(100 * time.Millisecond)
+	_, err = api.Uploads.UploadPhotoFromUrl(context.Background(), stalled.URL+"/image.png")
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	require.True(t, netErr.Timeout())
+}
@@ -0,0 +1,101 @@
+package maxbot
+
+import "github.com/rectid/max-bot-api-client-go/schemes"
+
+// ReplyKeyboard implements builder for reply keyboard shown instead of user's keyboard
+type ReplyKeyboard struct {
+	rows         []*ReplyKeyboardRow
+	direct       bool
+	directUserID int64
+}
+
+// AddRow adds row to reply keyboard
+func (k *ReplyKeyboard) AddRow() *ReplyKeyboardRow {
+	kr := &ReplyKeyboardRow{}
+	k.rows = append(k.rows, kr)
+	return kr
+}
+
+// SetDirect shows keyboard in chat only to user who mentioned bot or replied to its message
+func (k *ReplyKeyboard) SetDirect(direct bool) *ReplyKeyboard {
+	k.direct = direct
+	return k
+}
+
+// SetDirectUser shows keyboard in chat only to given participant
+func (k *ReplyKeyboard) SetDirectUser(userID int64) *ReplyKeyboard {
+	k.directUserID = userID
+	return k
+}
+
+// Build returns reply keyboard request or *ValidationError if its buttons or payloads exceed API limits
+func (k *ReplyKeyboard) Build() (*schemes.ReplyKeyboardAttachmentRequest, error) {
+	request := k.build()
+	verr := &ValidationError{}
+	validateKeyboard(verr, "keyboard", request.Buttons)
+	if len(verr.Violations) > 0 {
+		return request, verr
+	}
+	return request, nil
+}
+
+// build returns reply keyboard request unchecked for Message.AddReplyKeyboard
+func (k *ReplyKeyboard) build() *schemes.ReplyKeyboardAttachmentRequest {
+	buttons := make([][]schemes.ReplyButtonInterface, 0, len(k.rows))
+	for _, r := range k.rows {
+		buttons = append(buttons, r.Build())
+	}
+	request := schemes.NewReplyKeyboardAttachmentRequest(buttons)
+	request.Direct = k.direct
+	request.DirectUserId = k.directUserID
+	return request
+}
+
+// ReplyKeyboardRow represents reply buttons row
+type ReplyKeyboardRow struct {
+	cols []schemes.ReplyButtonInterface
+}
+
+// Build returns result reply keyboard row
+func (k *ReplyKeyboardRow) Build() []schemes.ReplyButtonInterface {
+	return k.cols
+}
+
+// AddMessage button. Pressing it sends message with button text on behalf of user, payload is delivered as data attachment
+func (k *ReplyKeyboardRow) AddMessage(text string, intent schemes.Intent, payload string) *ReplyKeyboardRow {
+	b := schemes.SendMessageButton{
+		Intent: intent,
+		ReplyButton: schemes.ReplyButton{
+			Text:    text,
+			Payload: payload,
+			Type:    schemes.MESSAGE,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
+
+// AddGeolocation button. If quick is true, location is sent without user's confirmation
+func (k *ReplyKeyboardRow) AddGeolocation(text string, quick bool) *ReplyKeyboardRow {
+	b := schemes.SendGeoLocationButton{
+		Quick: quick,
+		ReplyButton: schemes.ReplyButton{
+			Text: text,
+			Type: schemes.USER_GEOLOCATION,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
+
+// AddContact button
+func (k *ReplyKeyboardRow) AddContact(text string) *ReplyKeyboardRow {
+	b := schemes.SendContactButton{
+		ReplyButton: schemes.ReplyButton{
+			Text: text,
+			Type: schemes.USER_CONTACT,
+		},
+	}
+	k.cols = append(k.cols, b)
+	return k
+}
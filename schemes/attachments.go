@@ -0,0 +1,195 @@
+package schemes
+
+// AttachmentVisitor is implemented by types that handle received attachments by their concrete type.
+// Embed BaseAttachmentVisitor to implement only the methods you need
+type AttachmentVisitor interface {
+	VisitPhoto(*PhotoAttachment) error
+	VisitVideo(*VideoAttachment) error
+	VisitAudio(*AudioAttachment) error
+	VisitFile(*FileAttachment) error
+	VisitSticker(*StickerAttachment) error
+	VisitContact(*ContactAttachment) error
+	VisitLocation(*LocationAttachment) error
+	VisitShare(*ShareAttachment) error
+	VisitInlineKeyboard(*InlineKeyboardAttachment) error
+	VisitReplyKeyboard(*ReplyKeyboardAttachment) error
+	VisitData(*DataAttachment) error
+	VisitUnknown(AttachmentInterface) error
+}
+
+// BaseAttachmentVisitor is AttachmentVisitor that ignores every attachment
+type BaseAttachmentVisitor struct{}
+
+func (BaseAttachmentVisitor) VisitPhoto(*PhotoAttachment) error                   { return nil }
+func (BaseAttachmentVisitor) VisitVideo(*VideoAttachment) error                   { return nil }
+func (BaseAttachmentVisitor) VisitAudio(*AudioAttachment) error                   { return nil }
+func (BaseAttachmentVisitor) VisitFile(*FileAttachment) error                     { return nil }
+func (BaseAttachmentVisitor) VisitSticker(*StickerAttachment) error               { return nil }
+func (BaseAttachmentVisitor) VisitContact(*ContactAttachment) error               { return nil }
+func (BaseAttachmentVisitor) VisitLocation(*LocationAttachment) error             { return nil }
+func (BaseAttachmentVisitor) VisitShare(*ShareAttachment) error                   { return nil }
+func (BaseAttachmentVisitor) VisitInlineKeyboard(*InlineKeyboardAttachment) error { return nil }
+func (BaseAttachmentVisitor) VisitReplyKeyboard(*ReplyKeyboardAttachment) error   { return nil }
+func (BaseAttachmentVisitor) VisitData(*DataAttachment) error                     { return nil }
+func (BaseAttachmentVisitor) VisitUnknown(AttachmentInterface) error              { return nil }
+
+// VisitAttachment calls the visitor method matching the concrete type of attachment
+func VisitAttachment(attachment AttachmentInterface, visitor AttachmentVisitor) error {
+	switch a := attachment.(type) {
+	case *PhotoAttachment:
+		return visitor.VisitPhoto(a)
+	case *VideoAttachment:
+		return visitor.VisitVideo(a)
+	case *AudioAttachment:
+		return visitor.VisitAudio(a)
+	case *FileAttachment:
+		return visitor.VisitFile(a)
+	case *StickerAttachment:
+		return visitor.VisitSticker(a)
+	case *ContactAttachment:
+		return visitor.VisitContact(a)
+	case *LocationAttachment:
+		return visitor.VisitLocation(a)
+	case *ShareAttachment:
+		return visitor.VisitShare(a)
+	case *InlineKeyboardAttachment:
+		return visitor.VisitInlineKeyboard(a)
+	case *ReplyKeyboardAttachment:
+		return visitor.VisitReplyKeyboard(a)
+	case *DataAttachment:
+		return visitor.VisitData(a)
+	default:
+		return visitor.VisitUnknown(attachment)
+	}
+}
+
+// VisitAttachments calls visitor for every attachment of message in order. Stops on the first error
+func (b MessageBody) VisitAttachments(visitor AttachmentVisitor) error {
+	for _, a := range b.Attachments {
+		attachment, ok := a.(AttachmentInterface)
+		if !ok {
+			continue
+		}
+		if err := VisitAttachment(attachment, visitor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Photos returns all image attachments of message
+func (b MessageBody) Photos() []*PhotoAttachment {
+	return attachmentsOf[*PhotoAttachment](b)
+}
+
+// Videos returns all video attachments of message
+func (b MessageBody) Videos() []*VideoAttachment {
+	return attachmentsOf[*VideoAttachment](b)
+}
+
+// Audios returns all audio attachments of message
+func (b MessageBody) Audios() []*AudioAttachment {
+	return attachmentsOf[*AudioAttachment](b)
+}
+
+// Files returns all file attachments of message
+func (b MessageBody) Files() []*FileAttachment {
+	return attachmentsOf[*FileAttachment](b)
+}
+
+// Stickers returns all sticker attachments of message
+func (b MessageBody) Stickers() []*StickerAttachment {
+	return attachmentsOf[*StickerAttachment](b)
+}
+
+// Shares returns all link preview attachments of message
+func (b MessageBody) Shares() []*ShareAttachment {
+	return attachmentsOf[*ShareAttachment](b)
+}
+
+// Location returns location attachment of message or nil
+func (b MessageBody) Location() *LocationAttachment {
+	return firstAttachmentOf[*LocationAttachment](b)
+}
+
+// Contact returns contact attachment of message or nil
+func (b MessageBody) Contact() *ContactAttachment {
+	return firstAttachmentOf[*ContactAttachment](b)
+}
+
+// Keyboard returns inline keyboard attachment of message or nil
+func (b MessageBody) Keyboard() *InlineKeyboardAttachment {
+	return firstAttachmentOf[*InlineKeyboardAttachment](b)
+}
+
+// ReplyKeyboard returns reply keyboard attachment of message or nil
+func (b MessageBody) ReplyKeyboard() *ReplyKeyboardAttachment {
+	return firstAttachmentOf[*ReplyKeyboardAttachment](b)
+}
+
+// Data returns payload of reply keyboard button pressed by user or empty string
+func (b MessageBody) Data() string {
+	if data := firstAttachmentOf[*DataAttachment](b); data != nil {
+		return data.Data
+	}
+	return ""
+}
+
+func attachmentsOf[T AttachmentInterface](b MessageBody) []T {
+	var result []T
+	for _, a := range b.Attachments {
+		if attachment, ok := a.(T); ok {
+			result = append(result, attachment)
+		}
+	}
+	return result
+}
+
+func firstAttachmentOf[T AttachmentInterface](b MessageBody) T {
+	var zero T
+	for _, a := range b.Attachments {
+		if attachment, ok := a.(T); ok {
+			return attachment
+		}
+	}
+	return zero
+}
+
+// NewAttachmentRequestFrom builds request to send received attachment again.
+// Media is referenced by its token, so nothing is uploaded twice.
+// Returns false if attachment can not be sent again
+func NewAttachmentRequestFrom(attachment AttachmentInterface) (interface{}, bool) {
+	switch a := attachment.(type) {
+	case *PhotoAttachment:
+		return NewPhotoAttachmentRequest(PhotoAttachmentRequestPayload{Token: a.Payload.Token}), true
+	case *VideoAttachment:
+		return NewVideoAttachmentRequest(UploadedInfo{Token: a.Payload.Token}), true
+	case *AudioAttachment:
+		return NewAudioAttachmentRequest(UploadedInfo{Token: a.Payload.Token}), true
+	case *FileAttachment:
+		return NewFileAttachmentRequest(UploadedInfo{Token: a.Payload.Token}), true
+	case *StickerAttachment:
+		return NewStickerAttachmentRequest(StickerAttachmentRequestPayload{Code: a.Payload.Code}), true
+	case *ContactAttachment:
+		payload := ContactAttachmentRequestPayload{VcfInfo: a.Payload.VcfInfo}
+		if a.Payload.TamInfo != nil {
+			payload.Name = a.Payload.TamInfo.Name
+			payload.ContactId = a.Payload.TamInfo.UserId
+		}
+		return NewContactAttachmentRequest(payload), true
+	case *LocationAttachment:
+		return NewLocationAttachmentRequest(a.Latitude, a.Longitude), true
+	case *ShareAttachment:
+		payload := a.Payload
+		if payload.Token != "" {
+			payload.Url = ""
+		}
+		return NewShareAttachmentRequest(payload), true
+	case *InlineKeyboardAttachment:
+		return NewInlineKeyboardAttachmentRequest(a.Payload), true
+	case *ReplyKeyboardAttachment:
+		return NewReplyKeyboardAttachmentRequest(a.Buttons), true
+	default:
+		return nil, false
+	}
+}
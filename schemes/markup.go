@@ -0,0 +1,142 @@
+package schemes
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf16"
+)
+
+// MarkupElement is generic schema of message text markup
+type MarkupElement struct {
+	Type   MarkupType `json:"type"`   // Type of the markup element
+	From   int        `json:"from"`   // Element start index (zero-based) in text. Counted in UTF-16 code units
+	Length int        `json:"length"` // Length of the markup element. Counted in UTF-16 code units
+}
+
+func (m MarkupElement) GetMarkupType() MarkupType {
+	return m.Type
+}
+
+func (m MarkupElement) GetFrom() int {
+	return m.From
+}
+
+func (m MarkupElement) GetLength() int {
+	return m.Length
+}
+
+type MarkupElementInterface interface {
+	MarkupInterface
+	GetFrom() int
+	GetLength() int
+}
+
+// Represents **bold** in text
+type StrongMarkup struct {
+	MarkupElement
+}
+
+// Represents *italic* in text
+type EmphasizedMarkup struct {
+	MarkupElement
+}
+
+// Represents `monospaced` or ```code``` block in text
+type MonospacedMarkup struct {
+	MarkupElement
+}
+
+// Represents link in text
+type LinkMarkup struct {
+	MarkupElement
+	Url string `json:"url"` // Link's URL
+}
+
+// Represents ~strikethrough~ block in text
+type StrikethroughMarkup struct {
+	MarkupElement
+}
+
+// Represents ++underlined++ part of the text
+type UnderlineMarkup struct {
+	MarkupElement
+}
+
+// Represents header part of the text
+type HeadingMarkup struct {
+	MarkupElement
+}
+
+// Represents user mention in text. Mention can be both by user's username or ID if user doesn't have username
+type UserMentionMarkup struct {
+	MarkupElement
+	UserLink string `json:"user_link,omitempty"` // `@username` of mentioned user
+	UserId   int64  `json:"user_id,omitempty"`   // Identifier of mentioned user without username
+}
+
+// Represents a highlighted piece of text
+type HighlightedMarkup struct {
+	MarkupElement
+}
+
+// markupTypeMap maps markup types to their corresponding struct constructors
+var markupTypeMap = map[MarkupType]func() MarkupElementInterface{
+	MarkupStrong:        func() MarkupElementInterface { return new(StrongMarkup) },
+	MarkupEmphasized:    func() MarkupElementInterface { return new(EmphasizedMarkup) },
+	MarkupMonospaced:    func() MarkupElementInterface { return new(MonospacedMarkup) },
+	MarkupLink:          func() MarkupElementInterface { return new(LinkMarkup) },
+	MarkupStrikethrough: func() MarkupElementInterface { return new(StrikethroughMarkup) },
+	MarkupUnderline:     func() MarkupElementInterface { return new(UnderlineMarkup) },
+	MarkupHeading:       func() MarkupElementInterface { return new(HeadingMarkup) },
+	MarkupUser:          func() MarkupElementInterface { return new(UserMentionMarkup) },
+	MarkupHighlighted:   func() MarkupElementInterface { return new(HighlightedMarkup) },
+}
+
+// UnmarshalMarkupElement converts raw JSON bytes to the appropriate markup element type.
+// Unknown markup types are returned as *MarkupElement
+func UnmarshalMarkupElement(data []byte) (MarkupElementInterface, error) {
+	baseElement := &MarkupElement{}
+	if err := json.Unmarshal(data, baseElement); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base markup: %w", err)
+	}
+
+	constructor, exists := markupTypeMap[baseElement.GetMarkupType()]
+	if !exists {
+		return baseElement, nil
+	}
+
+	element := constructor()
+	if err := json.Unmarshal(data, element); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal markup of type %s: %w", baseElement.GetMarkupType(), err)
+	}
+
+	return element, nil
+}
+
+// Mentions returns all user mentions of message text
+func (b MessageBody) Mentions() []*UserMentionMarkup {
+	return markupOf[*UserMentionMarkup](b)
+}
+
+// Links returns all links of message text
+func (b MessageBody) Links() []*LinkMarkup {
+	return markupOf[*LinkMarkup](b)
+}
+
+// MarkupText returns part of message text covered by markup element
+func (b MessageBody) MarkupText(element MarkupElementInterface) string {
+	units := utf16.Encode([]rune(b.Text))
+	from := min(max(element.GetFrom(), 0), len(units))
+	to := min(max(from+element.GetLength(), from), len(units))
+	return string(utf16.Decode(units[from:to]))
+}
+
+func markupOf[T MarkupElementInterface](b MessageBody) []T {
+	var result []T
+	for _, m := range b.Markup {
+		if element, ok := m.(T); ok {
+			result = append(result, element)
+		}
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package schemes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// After pressing this type of button client will send a message on behalf of user with given payload
+type ReplyButton struct {
+	Type    ButtonType `json:"type,omitempty"`
+	Text    string     `json:"text"`              // Visible text of button
+	Payload string     `json:"payload,omitempty"` // Button payload
+}
+
+func (b ReplyButton) GetType() ButtonType {
+	return b.Type
+}
+
+func (b ReplyButton) GetText() string {
+	return b.Text
+}
+
+func (b ReplyButton) GetPayload() string {
+	return b.Payload
+}
+
+type ReplyButtonInterface interface {
+	ButtonInterface
+	GetPayload() string
+}
+
+// After pressing this type of button client will send a message on behalf of user with given payload
+type SendMessageButton struct {
+	ReplyButton
+	Intent Intent `json:"intent,omitempty"` // Intent of button. Affects clients representation
+}
+
+// After pressing this type of button client sends new message with attachment of current user geo location
+type SendGeoLocationButton struct {
+	ReplyButton
+	Quick bool `json:"quick,omitempty"` // If *true*, sends location without asking user's confirmation
+}
+
+// After pressing this type of button client sends new message with attachment of current user contact
+type SendContactButton struct {
+	ReplyButton
+}
+
+// replyButtonTypeMap maps reply button types to their corresponding struct constructors
+var replyButtonTypeMap = map[ButtonType]func() ReplyButtonInterface{
+	MESSAGE:          func() ReplyButtonInterface { return new(SendMessageButton) },
+	USER_GEOLOCATION: func() ReplyButtonInterface { return new(SendGeoLocationButton) },
+	USER_CONTACT:     func() ReplyButtonInterface { return new(SendContactButton) },
+}
+
+// UnmarshalReplyButton converts raw JSON bytes to the appropriate reply button type.
+// Buttons without type are SendMessageButton, unknown button types are returned as *ReplyButton
+func UnmarshalReplyButton(data []byte) (ReplyButtonInterface, error) {
+	baseButton := &ReplyButton{}
+	if err := json.Unmarshal(data, baseButton); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base reply button: %w", err)
+	}
+	if baseButton.Type == "" {
+		baseButton.Type = MESSAGE
+	}
+
+	constructor, exists := replyButtonTypeMap[baseButton.GetType()]
+	if !exists {
+		return baseButton, nil
+	}
+
+	button := constructor()
+	if err := json.Unmarshal(data, button); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reply button of type %s: %w", baseButton.GetType(), err)
+	}
+
+	return button, nil
+}
+
+// unmarshalReplyButtons decodes every reply button into its concrete type
+func unmarshalReplyButtons(rawRows [][]json.RawMessage) ([][]ReplyButtonInterface, error) {
+	rows := make([][]ReplyButtonInterface, 0, len(rawRows))
+	for _, rawRow := range rawRows {
+		row := make([]ReplyButtonInterface, 0, len(rawRow))
+		for _, rawButton := range rawRow {
+			button, err := UnmarshalReplyButton(rawButton)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, button)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// Custom reply keyboard in message
+type ReplyKeyboardAttachment struct {
+	Attachment
+	Buttons [][]ReplyButtonInterface `json:"buttons"`
+}
+
+// UnmarshalJSON decodes every button of the keyboard into its concrete type
+func (a *ReplyKeyboardAttachment) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Type    AttachmentType      `json:"type"`
+		Buttons [][]json.RawMessage `json:"buttons"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	buttons, err := unmarshalReplyButtons(raw.Buttons)
+	if err != nil {
+		return err
+	}
+	a.Type = raw.Type
+	a.Buttons = buttons
+	return nil
+}
+
+// Request to attach reply keyboard to message
+type ReplyKeyboardAttachmentRequest struct {
+	AttachmentRequest
+	Direct       bool                     `json:"direct,omitempty"`         // Applicable only for chats. If `true` keyboard will be shown only for user bot mentioned or replied
+	DirectUserId int64                    `json:"direct_user_id,omitempty"` // If set, reply keyboard will only be shown to this participant in chat
+	Buttons      [][]ReplyButtonInterface `json:"buttons"`                  // Two-dimensional array of buttons
+}
+
+func NewReplyKeyboardAttachmentRequest(buttons [][]ReplyButtonInterface) *ReplyKeyboardAttachmentRequest {
+	return &ReplyKeyboardAttachmentRequest{Buttons: buttons, AttachmentRequest: AttachmentRequest{Type: AttachmentReplyKeyboard}}
+}
+
+// Attachment contains payload sent through `SendMessageButton`
+type DataAttachment struct {
+	Attachment
+	Data string `json:"data"`
+}
@@ -2,6 +2,7 @@ package schemes
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -13,15 +14,17 @@ type ActionRequestBody struct {
 type AttachmentType string
 
 const (
-	AttachmentImage    AttachmentType = "image"
-	AttachmentVideo                   = "video"
-	AttachmentAudio                   = "audio"
-	AttachmentFile                    = "file"
-	AttachmentContact                 = "contact"
-	AttachmentSticker                 = "sticker"
-	AttachmentShare                   = "share"
-	AttachmentLocation                = "location"
-	AttachmentKeyboard                = "inline_keyboard"
+	AttachmentImage         AttachmentType = "image"
+	AttachmentVideo                        = "video"
+	AttachmentAudio                        = "audio"
+	AttachmentFile                         = "file"
+	AttachmentContact                      = "contact"
+	AttachmentSticker                      = "sticker"
+	AttachmentShare                        = "share"
+	AttachmentLocation                     = "location"
+	AttachmentKeyboard                     = "inline_keyboard"
+	AttachmentReplyKeyboard                = "reply_keyboard"
+	AttachmentData                         = "data"
 )
 
 // Generic schema representing message attachment
@@ -37,6 +40,43 @@ type AttachmentInterface interface {
 	GetAttachmentType() AttachmentType
 }
 
+// attachmentTypeMap maps attachment types to their corresponding struct constructors
+var attachmentTypeMap = map[AttachmentType]func() AttachmentInterface{
+	AttachmentAudio:         func() AttachmentInterface { return new(AudioAttachment) },
+	AttachmentContact:       func() AttachmentInterface { return new(ContactAttachment) },
+	AttachmentFile:          func() AttachmentInterface { return new(FileAttachment) },
+	AttachmentImage:         func() AttachmentInterface { return new(PhotoAttachment) },
+	AttachmentKeyboard:      func() AttachmentInterface { return new(InlineKeyboardAttachment) },
+	AttachmentReplyKeyboard: func() AttachmentInterface { return new(ReplyKeyboardAttachment) },
+	AttachmentData:          func() AttachmentInterface { return new(DataAttachment) },
+	AttachmentLocation:      func() AttachmentInterface { return new(LocationAttachment) },
+	AttachmentShare:         func() AttachmentInterface { return new(ShareAttachment) },
+	AttachmentSticker:       func() AttachmentInterface { return new(StickerAttachment) },
+	AttachmentVideo:         func() AttachmentInterface { return new(VideoAttachment) },
+}
+
+// UnmarshalAttachment converts raw JSON bytes to the appropriate attachment type.
+// Unknown attachment types are returned as *Attachment
+func UnmarshalAttachment(data []byte) (AttachmentInterface, error) {
+	baseAttachment := &Attachment{}
+	if err := json.Unmarshal(data, baseAttachment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base attachment: %w", err)
+	}
+
+	attachmentType := baseAttachment.GetAttachmentType()
+	constructor, exists := attachmentTypeMap[attachmentType]
+	if !exists {
+		return baseAttachment, nil
+	}
+
+	attachment := constructor()
+	if err := json.Unmarshal(data, attachment); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal attachment of type %s: %w", attachmentType, err)
+	}
+
+	return attachment, nil
+}
+
 type AttachmentPayload struct {
 	// Media attachment URL
 	Url string `json:"url"`
@@ -103,6 +143,38 @@ type ButtonInterface interface {
 	GetText() string
 }
 
+// buttonTypeMap maps button types to their corresponding struct constructors
+var buttonTypeMap = map[ButtonType]func() ButtonInterface{
+	CALLBACK:    func() ButtonInterface { return new(CallbackButton) },
+	LINK:        func() ButtonInterface { return new(LinkButton) },
+	CONTACT:     func() ButtonInterface { return new(RequestContactButton) },
+	GEOLOCATION: func() ButtonInterface { return new(RequestGeoLocationButton) },
+	CHAT_BUTTON: func() ButtonInterface { return new(ChatButton) },
+	MESSAGE:     func() ButtonInterface { return new(MessageButton) },
+	OPEN_APP:    func() ButtonInterface { return new(OpenAppButton) },
+}
+
+// UnmarshalButton converts raw JSON bytes to the appropriate button type.
+// Unknown button types are returned as *Button
+func UnmarshalButton(data []byte) (ButtonInterface, error) {
+	baseButton := &Button{}
+	if err := json.Unmarshal(data, baseButton); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal base button: %w", err)
+	}
+
+	constructor, exists := buttonTypeMap[baseButton.GetType()]
+	if !exists {
+		return baseButton, nil
+	}
+
+	button := constructor()
+	if err := json.Unmarshal(data, button); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal button of type %s: %w", baseButton.GetType(), err)
+	}
+
+	return button, nil
+}
+
 // Send this object when your bots wants to react to when a button is pressed
 type CallbackAnswer struct {
 	Message      *NewMessageBody `json:"message,omitempty"`      // Fill this if you want to modify current message
@@ -300,6 +372,13 @@ const (
 	CALLBACK    ButtonType = "callback"
 	CONTACT     ButtonType = "request_contact"
 	GEOLOCATION ButtonType = "request_geo_location"
+	CHAT_BUTTON ButtonType = "chat"
+	MESSAGE     ButtonType = "message"
+	OPEN_APP    ButtonType = "open_app"
+
+	// Reply keyboard buttons. Reply keyboard uses MESSAGE for SendMessageButton
+	USER_GEOLOCATION ButtonType = "user_geo_location"
+	USER_CONTACT     ButtonType = "user_contact"
 )
 
 // Intent : Intent of button
@@ -317,10 +396,59 @@ type Keyboard struct {
 	Buttons [][]ButtonInterface `json:"buttons"`
 }
 
+// UnmarshalJSON decodes every button of the keyboard into its concrete type
+func (k *Keyboard) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Buttons [][]json.RawMessage `json:"buttons"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	k.Buttons = make([][]ButtonInterface, 0, len(raw.Buttons))
+	for _, rawRow := range raw.Buttons {
+		row := make([]ButtonInterface, 0, len(rawRow))
+		for _, rawButton := range rawRow {
+			button, err := UnmarshalButton(rawButton)
+			if err != nil {
+				return err
+			}
+			row = append(row, button)
+		}
+		k.Buttons = append(k.Buttons, row)
+	}
+
+	return nil
+}
+
 // After pressing this type of button user follows the link it contains
 type LinkButton struct {
 	Button
-	Url string `json:"url"`
+	Url    string `json:"url"`
+	Intent Intent `json:"intent,omitempty"` // Intent of button. Affects clients representation
+}
+
+// Button that creates new chat as soon as the first user clicked on it.
+// Bot will be added to chat participants as administrator. Message author will be owner of the chat
+type ChatButton struct {
+	Button
+	ChatTitle       string `json:"chat_title"`                 // Title of chat to be created
+	ChatDescription string `json:"chat_description,omitempty"` // Chat description
+	StartPayload    string `json:"start_payload,omitempty"`    // Start payload will be sent to bot as soon as chat created
+	Uuid            int64  `json:"uuid,omitempty"`             // Unique button identifier across all chat buttons in keyboard. Server generates it when button initially posted. Reuse it when you edit the message
+}
+
+// After pressing this type of button it sends message from user in chat
+type MessageButton struct {
+	Button
+}
+
+// After pressing this type of button client opens mini app of bot
+type OpenAppButton struct {
+	Button
+	WebApp    string `json:"web_app,omitempty"`    // Public name (username) of bot or link to it whose mini app should be opened
+	ContactId int64  `json:"contact_id,omitempty"` // Identifier of bot whose mini app should be opened
+	Payload   string `json:"payload,omitempty"`    // Start parameter passed to mini app
 }
 
 type LinkedMessage struct {
@@ -330,6 +458,15 @@ type LinkedMessage struct {
 	Message MessageBody     `json:"message"`
 }
 
+// OriginalSender returns user who sent linked message. Returns nil if message has been posted on behalf of a channel
+func (l LinkedMessage) OriginalSender() *User {
+	if l.Sender.UserId == 0 {
+		return nil
+	}
+	sender := l.Sender
+	return &sender
+}
+
 type LocationAttachment struct {
 	Attachment
 	Latitude  float64 `json:"latitude"`
@@ -360,31 +497,83 @@ type Message struct {
 	Link      *LinkedMessage `json:"link,omitempty"`   // Forwarder or replied message
 	Body      MessageBody    `json:"body"`             // Body of created message. Text + attachments. Could be null if message contains only forwarded message
 	Stat      *MessageStat   `json:"stat,omitempty"`   // Message statistics. Available only for channels in [GET:/messages](#operation/getMessages) context
+	Url       string         `json:"url,omitempty"`    // Message public URL. Can be `null` for dialogs or non-public chats/channels
+}
+
+// MessageSource is implemented by types carrying a received message
+type MessageSource interface {
+	GetMessage() *Message
+}
+
+// GetMessage returns the message itself
+func (m *Message) GetMessage() *Message {
+	return m
+}
+
+// IsForward reports whether message contains forwarded message
+func (m Message) IsForward() bool {
+	return m.Link != nil && m.Link.Type == FORWARD
+}
+
+// IsReply reports whether message is a reply to another message
+func (m Message) IsReply() bool {
+	return m.Link != nil && m.Link.Type == REPLY
 }
 
 // Schema representing body of message
 type MessageBody struct {
-	Mid            string            `json:"mid"`            // Unique identifier of message
-	Seq            int64             `json:"seq"`            // Sequence identifier of message in chat
-	Text           string            `json:"text,omitempty"` // Message text
-	RawAttachments []json.RawMessage `json:"attachments"`    // Message attachments. Could be one of `Attachment` type. See description of this schema
-	Attachments    []interface{}
-	ReplyTo        string `json:"reply_to,omitempty"` // In case this message is reply to another, it is the unique identifier of the replied message
+	Mid            string                   `json:"mid"`                // Unique identifier of message
+	Seq            int64                    `json:"seq"`                // Sequence identifier of message in chat
+	Text           string                   `json:"text,omitempty"`     // Message text
+	RawAttachments []json.RawMessage        `json:"attachments"`        // Message attachments. Could be one of `Attachment` type. See description of this schema
+	Attachments    []interface{}            `json:"-"`                  // Decoded RawAttachments. Every element implements AttachmentInterface
+	ReplyTo        string                   `json:"reply_to,omitempty"` // In case this message is reply to another, it is the unique identifier of the replied message
+	RawMarkup      []json.RawMessage        `json:"markup,omitempty"`   // Message text markup. Could be one of `MarkupElement` type
+	Markup         []MarkupElementInterface `json:"-"`                  // Decoded RawMarkup
+}
+
+// UnmarshalJSON decodes message body and converts raw attachments and markup to their concrete types
+func (b *MessageBody) UnmarshalJSON(data []byte) error {
+	type messageBody MessageBody
+	if err := json.Unmarshal(data, (*messageBody)(b)); err != nil {
+		return err
+	}
+
+	b.Attachments = nil
+	for _, rawAttachment := range b.RawAttachments {
+		attachment, err := UnmarshalAttachment(rawAttachment)
+		if err != nil {
+			return fmt.Errorf("failed to process attachment: %w", err)
+		}
+		b.Attachments = append(b.Attachments, attachment)
+	}
+
+	b.Markup = nil
+	for _, rawMarkup := range b.RawMarkup {
+		element, err := UnmarshalMarkupElement(rawMarkup)
+		if err != nil {
+			return fmt.Errorf("failed to process markup: %w", err)
+		}
+		b.Markup = append(b.Markup, element)
+	}
+
+	return nil
 }
 
 type UpdateType string
 
 const (
-	TypeMessageCallback  UpdateType = "message_callback"
-	TypeMessageCreated   UpdateType = "message_created"
-	TypeMessageRemoved   UpdateType = "message_removed"
-	TypeMessageEdited    UpdateType = "message_edited"
-	TypeBotAdded         UpdateType = "bot_added"
-	TypeBotRemoved       UpdateType = "bot_removed"
-	TypeUserAdded        UpdateType = "user_added"
-	TypeUserRemoved      UpdateType = "user_removed"
-	TypeBotStarted       UpdateType = "bot_started"
-	TypeChatTitleChanged UpdateType = "chat_title_changed"
+	TypeMessageCallback    UpdateType = "message_callback"
+	TypeMessageCreated     UpdateType = "message_created"
+	TypeMessageRemoved     UpdateType = "message_removed"
+	TypeMessageEdited      UpdateType = "message_edited"
+	TypeBotAdded           UpdateType = "bot_added"
+	TypeBotRemoved         UpdateType = "bot_removed"
+	TypeUserAdded          UpdateType = "user_added"
+	TypeUserRemoved        UpdateType = "user_removed"
+	TypeBotStarted         UpdateType = "bot_started"
+	TypeChatTitleChanged   UpdateType = "chat_title_changed"
+	TypeMessageChatCreated UpdateType = "message_chat_created"
 )
 
 // MessageLinkType : Type of linked message
@@ -401,8 +590,25 @@ type MarkupType string
 
 // List of MarkupType
 const (
-	MarkupUser MarkupType = "user_mention"
-	MarkupBot  MarkupType = "bot_mention"
+	MarkupUser          MarkupType = "user_mention"
+	MarkupBot           MarkupType = "bot_mention"
+	MarkupStrong        MarkupType = "strong"
+	MarkupEmphasized    MarkupType = "emphasized"
+	MarkupMonospaced    MarkupType = "monospaced"
+	MarkupLink          MarkupType = "link"
+	MarkupStrikethrough MarkupType = "strikethrough"
+	MarkupUnderline     MarkupType = "underline"
+	MarkupHeading       MarkupType = "heading"
+	MarkupHighlighted   MarkupType = "highlighted"
+)
+
+// TextFormat : Message text format
+type TextFormat string
+
+// List of TextFormat
+const (
+	FormatMarkdown TextFormat = "markdown"
+	FormatHTML     TextFormat = "html"
 )
 
 // Paginated list of messages
@@ -452,6 +658,7 @@ type MarkUp struct {
 	Length int        `json:"length"`            // length of marker message
 	UserId int64      `json:"user_id,omitempty"` // User identifier, if message was sent to user
 	Type   MarkupType `json:"type"`              // Type of markup
+	Url    string     `json:"url,omitempty"`     // Link's URL for link markup
 }
 
 type NewMessageLink struct {
@@ -498,6 +705,7 @@ type PhotoToken struct {
 // This is information you will receive as soon as an image uploaded
 type PhotoTokens struct {
 	Photos map[string]PhotoToken `json:"photos"`
+	Token  string                `json:"token,omitempty"` // Token of photo if upload server returned single token instead of sizes
 }
 
 // PinMessageBody defines model for PinMessageBody.
@@ -546,7 +754,26 @@ const (
 
 type ShareAttachment struct {
 	Attachment
-	Payload AttachmentPayload `json:"payload"`
+	Payload     ShareAttachmentPayload `json:"payload"`
+	Title       string                 `json:"title,omitempty"`       // Link preview title
+	Description string                 `json:"description,omitempty"` // Link preview description
+	ImageUrl    string                 `json:"image_url,omitempty"`   // Link preview image
+}
+
+// Payload of ShareAttachmentRequest
+type ShareAttachmentPayload struct {
+	Url   string `json:"url,omitempty"`   // URL attached to message as media preview
+	Token string `json:"token,omitempty"` // Attachment token
+}
+
+// Request to attach media preview of any external URL
+type ShareAttachmentRequest struct {
+	AttachmentRequest
+	Payload ShareAttachmentPayload `json:"payload"`
+}
+
+func NewShareAttachmentRequest(payload ShareAttachmentPayload) *ShareAttachmentRequest {
+	return &ShareAttachmentRequest{Payload: payload, AttachmentRequest: AttachmentRequest{Type: AttachmentShare}}
 }
 
 // Simple response to request
@@ -768,6 +995,23 @@ func (b ChatTitleChangedUpdate) GetChatID() int64 {
 	return b.ChatId
 }
 
+// Bot will get this update when chat has been created as soon as first user clicked chat button
+type MessageChatCreatedUpdate struct {
+	Update
+	Chat         Chat   `json:"chat"`                    // Created chat
+	MessageId    string `json:"message_id"`              // Message identifier where the button has been clicked
+	StartPayload string `json:"start_payload,omitempty"` // Payload from chat button
+}
+
+// GetUserID returns owner of created chat, that is the author of message with chat button
+func (b MessageChatCreatedUpdate) GetUserID() int64 {
+	return b.Chat.OwnerId
+}
+
+func (b MessageChatCreatedUpdate) GetChatID() int64 {
+	return b.Chat.ChatId
+}
+
 // You will get this `update` as soon as user presses button
 type MessageCallbackUpdate struct {
 	Update
@@ -780,7 +1024,15 @@ func (b MessageCallbackUpdate) GetUserID() int64 {
 }
 
 func (b MessageCallbackUpdate) GetChatID() int64 {
-	return 0
+	if b.Message == nil {
+		return 0
+	}
+	return b.Message.Recipient.ChatId
+}
+
+// GetMessage returns message with pressed button. Can be nil if message had been deleted
+func (b *MessageCallbackUpdate) GetMessage() *Message {
+	return b.Message
 }
 
 // You will get this `update` as soon as message is created
@@ -797,6 +1049,11 @@ func (b MessageCreatedUpdate) GetChatID() int64 {
 	return b.Message.Recipient.ChatId
 }
 
+// GetMessage returns newly created message
+func (b *MessageCreatedUpdate) GetMessage() *Message {
+	return &b.Message
+}
+
 func (b MessageCreatedUpdate) GetText() string {
 	return b.Message.Body.Text
 }
@@ -835,6 +1092,11 @@ func (b MessageEditedUpdate) GetChatID() int64 {
 	return b.Message.Recipient.ChatId
 }
 
+// GetMessage returns edited message
+func (b *MessageEditedUpdate) GetMessage() *Message {
+	return &b.Message
+}
+
 // You will get this `update` as soon as message is removed
 type MessageRemovedUpdate struct {
 	Update
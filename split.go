@@ -0,0 +1,247 @@
+package maxbot
+
+import (
+	"unicode/utf16"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+// MaxTextLength is the maximum length of message text accepted by API
+const MaxTextLength = 4000
+
+// TextPart is a part of long text that fits into one message
+type TextPart struct {
+	Text    string
+	Markups []schemes.MarkUp
+}
+
+// textRange is a half-open range of UTF-16 code units
+type textRange struct {
+	from, to int
+}
+
+// SplitText splits text into parts of at most limit UTF-16 code units.
+// It prefers paragraph, line, sentence and word boundaries and never cuts inside
+// markdown or html entities and markup elements unless a single entity is longer than limit.
+// Markup offsets are recalculated for every part
+func SplitText(text string, format schemes.TextFormat, markups []schemes.MarkUp, limit int) []TextPart {
+	if limit <= 0 {
+		limit = MaxTextLength
+	}
+
+	units := utf16.Encode([]rune(text))
+	var protected []textRange
+	switch format {
+	case schemes.FormatMarkdown:
+		protected = markdownRanges(units)
+	case schemes.FormatHTML:
+		protected = htmlRanges(units)
+	default:
+		for _, m := range markups {
+			protected = append(protected, textRange{from: m.From, to: m.From + m.Length})
+		}
+	}
+
+	var parts []TextPart
+	for start := skipSpaces(units, 0, len(units)); start < len(units); {
+		end := cutPoint(units, start, limit, protected)
+		to := end
+		for to > start && isSpace(units[to-1]) {
+			to--
+		}
+		if to > start {
+			parts = append(parts, TextPart{
+				Text:    string(utf16.Decode(units[start:to])),
+				Markups: clipMarkups(markups, start, to),
+			})
+		}
+		start = skipSpaces(units, end, len(units))
+	}
+
+	return parts
+}
+
+// cutPoint returns position where part starting at start should end
+func cutPoint(units []uint16, start int, limit int, protected []textRange) int {
+	end := start + limit
+	if end >= len(units) {
+		return len(units)
+	}
+
+	valid := func(p int) bool {
+		if isLowSurrogate(units[p]) {
+			return false
+		}
+		for _, r := range protected {
+			if r.from < p && p < r.to {
+				return false
+			}
+		}
+		return true
+	}
+
+	// paragraph, line and sentence boundaries are used only if they keep the part at least half full
+	boundaries := []func(p int) bool{
+		func(p int) bool { return p >= 2 && units[p-1] == '\n' && units[p-2] == '\n' },
+		func(p int) bool { return units[p-1] == '\n' },
+		func(p int) bool { return p >= 2 && isSpace(units[p-1]) && isSentenceEnd(units[p-2]) },
+	}
+	for _, boundary := range boundaries {
+		for p := end; p > start+limit/2; p-- {
+			if boundary(p) && valid(p) {
+				return p
+			}
+		}
+	}
+
+	for p := end; p > start; p-- {
+		if isSpace(units[p-1]) && valid(p) {
+			return p
+		}
+	}
+	for p := end; p > start; p-- {
+		if valid(p) {
+			return p
+		}
+	}
+	for p := end; p > start; p-- {
+		if !isLowSurrogate(units[p]) {
+			return p
+		}
+	}
+	return end
+}
+
+// clipMarkups returns markups inside [from, to) with offsets relative to from
+func clipMarkups(markups []schemes.MarkUp, from int, to int) []schemes.MarkUp {
+	var result []schemes.MarkUp
+	for _, m := range markups {
+		a, b := max(m.From, from), min(m.From+m.Length, to)
+		if a >= b {
+			continue
+		}
+		m.From = a - from
+		m.Length = b - a
+		result = append(result, m)
+	}
+	return result
+}
+
+// markdownRanges returns ranges of escapes, links and styled spans of markdown text
+func markdownRanges(units []uint16) []textRange {
+	delimiters := []string{"```", "**", "__", "~~", "++", "`", "*", "_"}
+	var ranges []textRange
+	for i := 0; i < len(units); {
+		if units[i] == '\\' {
+			ranges = append(ranges, textRange{from: i, to: min(i+2, len(units))})
+			i += 2
+			continue
+		}
+		if units[i] == '[' {
+			if mid := indexOf(units, i+1, "]("); mid >= 0 {
+				if end := indexOf(units, mid+2, ")"); end >= 0 {
+					ranges = append(ranges, textRange{from: i, to: end + 1})
+					i = end + 1
+					continue
+				}
+			}
+		}
+		matched := false
+		for _, d := range delimiters {
+			if !hasPrefixAt(units, i, d) {
+				continue
+			}
+			if end := indexOf(units, i+len(d), d); end >= 0 {
+				ranges = append(ranges, textRange{from: i, to: end + len(d)})
+				i = end + len(d)
+				matched = true
+			}
+			break
+		}
+		if !matched {
+			i++
+		}
+	}
+	return ranges
+}
+
+// htmlRanges returns ranges of tags, character references and top-level elements of html text
+func htmlRanges(units []uint16) []textRange {
+	var ranges []textRange
+	depth, top := 0, -1
+	for i := 0; i < len(units); {
+		switch units[i] {
+		case '<':
+			end := indexOf(units, i+1, ">")
+			if end < 0 {
+				return ranges
+			}
+			ranges = append(ranges, textRange{from: i, to: end + 1})
+			closing := i+1 < end && units[i+1] == '/'
+			selfClosing := units[end-1] == '/' || hasPrefixAt(units, i, "<br")
+			switch {
+			case closing:
+				depth = max(depth-1, 0)
+				if depth == 0 && top >= 0 {
+					ranges = append(ranges, textRange{from: top, to: end + 1})
+					top = -1
+				}
+			case !selfClosing:
+				if depth == 0 {
+					top = i
+				}
+				depth++
+			}
+			i = end + 1
+		case '&':
+			if end := indexOf(units[:min(i+11, len(units))], i+1, ";"); end >= 0 {
+				ranges = append(ranges, textRange{from: i, to: end + 1})
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return ranges
+}
+
+// indexOf returns position of ASCII substr in units starting from position from or -1
+func indexOf(units []uint16, from int, substr string) int {
+	for i := from; i+len(substr) <= len(units); i++ {
+		if hasPrefixAt(units, i, substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func hasPrefixAt(units []uint16, i int, prefix string) bool {
+	if i+len(prefix) > len(units) {
+		return false
+	}
+	for j := 0; j < len(prefix); j++ {
+		if units[i+j] != uint16(prefix[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+func skipSpaces(units []uint16, from int, to int) int {
+	for from < to && isSpace(units[from]) {
+		from++
+	}
+	return from
+}
+
+func isSpace(u uint16) bool {
+	return u == ' ' || u == '\n' || u == '\t' || u == '\r'
+}
+
+func isSentenceEnd(u uint16) bool {
+	return u == '.' || u == '!' || u == '?' || u == '…'
+}
+
+func isLowSurrogate(u uint16) bool {
+	return u >= 0xDC00 && u <= 0xDFFF
+}
@@ -0,0 +1,61 @@
+package maxbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitText(t *testing.T) {
+	texts := func(parts []TextPart) []string {
+		result := make([]string, 0, len(parts))
+		for _, p := range parts {
+			result = append(result, p.Text)
+		}
+		return result
+	}
+
+	t.Run("short text", func(t *testing.T) {
+		require.Equal(t, []string{"hello"}, texts(SplitText("hello", "", nil, 10)))
+	})
+
+	t.Run("paragraphs and sentences", func(t *testing.T) {
+		text := "First para.\n\nSecond one. Third sentence here"
+		require.Equal(t,
+			[]string{"First para.", "Second one.", "Third", "sentence", "here"},
+			texts(SplitText(text, "", nil, 12)))
+	})
+
+	t.Run("markdown entities", func(t *testing.T) {
+		text := "aa **bold text** [link text](https://x.y) bb"
+		require.Equal(t,
+			[]string{"aa **bold text**", "[link text](https://x.y)", "bb"},
+			texts(SplitText(text, schemes.FormatMarkdown, nil, 25)))
+	})
+
+	t.Run("html entities", func(t *testing.T) {
+		text := "aa <b>bold <i>it</i></b> &amp; cc"
+		require.Equal(t,
+			[]string{"aa", "<b>bold <i>it</i></b>", "&amp; cc"},
+			texts(SplitText(text, schemes.FormatHTML, nil, 22)))
+	})
+
+	t.Run("markup offsets", func(t *testing.T) {
+		text := "👍 one two three"
+		parts := SplitText(text, "", []schemes.MarkUp{
+			{From: 3, Length: 7, Type: schemes.MarkupStrong},
+			{From: 11, Length: 5, Type: schemes.MarkupEmphasized},
+		}, 11)
+		require.Equal(t, []TextPart{
+			{Text: "👍 one two", Markups: []schemes.MarkUp{{From: 3, Length: 7, Type: schemes.MarkupStrong}}},
+			{Text: "three", Markups: []schemes.MarkUp{{From: 0, Length: 5, Type: schemes.MarkupEmphasized}}},
+		}, parts)
+	})
+
+	t.Run("long word", func(t *testing.T) {
+		parts := SplitText(strings.Repeat("a", 25), "", nil, 10)
+		require.Equal(t, []string{strings.Repeat("a", 10), strings.Repeat("a", 10), strings.Repeat("a", 5)}, texts(parts))
+	})
+}
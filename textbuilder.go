@@ -0,0 +1,249 @@
+package maxbot
+
+import (
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `*`, `\*`, `_`, `\_`, `~`, `\~`, "`", "\\`",
+	`+`, `\+`, `[`, `\[`, `]`, `\]`, `(`, `\(`, `)`, `\)`,
+)
+
+var markdownURLEscaper = strings.NewReplacer(`(`, `%28`, `)`, `%29`, ` `, `%20`)
+
+// markdownTags maps markup types to markdown delimiters
+var markdownTags = map[schemes.MarkupType]string{
+	schemes.MarkupStrong:        "**",
+	schemes.MarkupEmphasized:    "_",
+	schemes.MarkupMonospaced:    "`",
+	schemes.MarkupStrikethrough: "~~",
+	schemes.MarkupUnderline:     "++",
+}
+
+// htmlTags maps markup types to html tags
+var htmlTags = map[schemes.MarkupType]string{
+	schemes.MarkupStrong:        "b",
+	schemes.MarkupEmphasized:    "i",
+	schemes.MarkupMonospaced:    "code",
+	schemes.MarkupStrikethrough: "s",
+	schemes.MarkupUnderline:     "u",
+}
+
+// TextBuilder implements builder for styled message text.
+// With markdown or html format user content is escaped and styles are rendered with format syntax.
+// With empty format text is sent as is and styles are sent as markup entities with UTF-16 offsets.
+// Heading and highlighted styles have no markdown and html syntax, so in markdown and html text they are rendered unstyled
+type TextBuilder struct {
+	format  schemes.TextFormat
+	text    strings.Builder
+	length  int
+	markups []schemes.MarkUp
+}
+
+// NewTextBuilder returns new text builder for given format. Pass empty format to use markup entities
+func NewTextBuilder(format schemes.TextFormat) *TextBuilder {
+	return &TextBuilder{format: format}
+}
+
+// NewTextBuilderFromBody returns text builder filled with text and markup of received message.
+// Use it to render received text to markdown or html
+func NewTextBuilderFromBody(format schemes.TextFormat, body schemes.MessageBody) *TextBuilder {
+	b := NewTextBuilder(format)
+	units := utf16.Encode([]rune(body.Text))
+
+	bounds := map[int]bool{0: true, len(units): true}
+	for _, m := range body.Markup {
+		bounds[min(max(m.GetFrom(), 0), len(units))] = true
+		bounds[min(max(m.GetFrom()+m.GetLength(), 0), len(units))] = true
+	}
+	points := make([]int, 0, len(bounds))
+	for p := range bounds {
+		points = append(points, p)
+	}
+	sort.Ints(points)
+
+	for i := 0; i+1 < len(points); i++ {
+		from, to := points[i], points[i+1]
+		var styles []schemes.MarkupType
+		link := schemes.MarkUp{}
+		for _, m := range body.Markup {
+			if m.GetFrom() > from || m.GetFrom()+m.GetLength() < to {
+				continue
+			}
+			switch m := m.(type) {
+			case *schemes.LinkMarkup:
+				link = schemes.MarkUp{Type: schemes.MarkupLink, Url: m.Url}
+			case *schemes.UserMentionMarkup:
+				if m.UserId != 0 {
+					link = schemes.MarkUp{Type: schemes.MarkupUser, UserId: m.UserId}
+				}
+			default:
+				styles = append(styles, m.GetMarkupType())
+			}
+		}
+		b.write(string(utf16.Decode(units[from:to])), styles, link)
+	}
+
+	return b
+}
+
+// Text adds plain text
+func (b *TextBuilder) Text(text string) *TextBuilder {
+	return b.Styled(text)
+}
+
+// Bold adds bold text
+func (b *TextBuilder) Bold(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupStrong)
+}
+
+// Italic adds italic text
+func (b *TextBuilder) Italic(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupEmphasized)
+}
+
+// Monospaced adds monospaced text
+func (b *TextBuilder) Monospaced(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupMonospaced)
+}
+
+// Strikethrough adds strikethrough text
+func (b *TextBuilder) Strikethrough(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupStrikethrough)
+}
+
+// Underline adds underlined text
+func (b *TextBuilder) Underline(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupUnderline)
+}
+
+// Heading adds heading text. It is rendered unstyled in markdown and html
+func (b *TextBuilder) Heading(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupHeading)
+}
+
+// Highlighted adds highlighted text. It is rendered unstyled in markdown and html
+func (b *TextBuilder) Highlighted(text string) *TextBuilder {
+	return b.Styled(text, schemes.MarkupHighlighted)
+}
+
+// Styled adds text with several styles at once. Link and user mention styles are ignored, use Link and Mention instead
+func (b *TextBuilder) Styled(text string, styles ...schemes.MarkupType) *TextBuilder {
+	b.write(text, styles, schemes.MarkUp{})
+	return b
+}
+
+// Link adds text following the url
+func (b *TextBuilder) Link(text string, url string, styles ...schemes.MarkupType) *TextBuilder {
+	b.write(text, styles, schemes.MarkUp{Type: schemes.MarkupLink, Url: url})
+	return b
+}
+
+// Mention adds user mention
+func (b *TextBuilder) Mention(text string, userID int64) *TextBuilder {
+	b.write(text, nil, schemes.MarkUp{Type: schemes.MarkupUser, UserId: userID})
+	return b
+}
+
+// Format returns text format of builder
+func (b *TextBuilder) Format() schemes.TextFormat {
+	return b.format
+}
+
+// String returns result text
+func (b *TextBuilder) String() string {
+	return b.text.String()
+}
+
+// Markups returns markup entities of text. Offsets and lengths are counted in UTF-16 code units of the visible text
+func (b *TextBuilder) Markups() []schemes.MarkUp {
+	return b.markups
+}
+
+func (b *TextBuilder) write(text string, styles []schemes.MarkupType, link schemes.MarkUp) {
+	length := utf16Len(text)
+	if length > 0 {
+		for _, style := range styles {
+			if style == schemes.MarkupLink || style == schemes.MarkupUser {
+				continue
+			}
+			b.markups = append(b.markups, schemes.MarkUp{From: b.length, Length: length, Type: style})
+		}
+		if link.Type != "" {
+			link.From = b.length
+			link.Length = length
+			b.markups = append(b.markups, link)
+		}
+	}
+	b.length += length
+
+	switch b.format {
+	case schemes.FormatMarkdown:
+		b.text.WriteString(renderMarkdown(text, styles, link))
+	case schemes.FormatHTML:
+		b.text.WriteString(renderHTML(text, styles, link))
+	default:
+		b.text.WriteString(text)
+	}
+}
+
+// renderMarkdown wraps text with delimiters. Leading and trailing whitespace is moved outside of delimiters,
+// because delimiter next to whitespace is not closed and is shown as is
+func renderMarkdown(text string, styles []schemes.MarkupType, link schemes.MarkUp) string {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return markdownEscaper.Replace(text)
+	}
+	start := strings.Index(text, core)
+	result := markdownEscaper.Replace(core)
+	for _, style := range styles {
+		if tag, ok := markdownTags[style]; ok {
+			result = tag + result + tag
+		}
+	}
+	switch link.Type {
+	case schemes.MarkupLink:
+		result = "[" + result + "](" + markdownURLEscaper.Replace(link.Url) + ")"
+	case schemes.MarkupUser:
+		result = "[" + result + "](" + userURL(link.UserId) + ")"
+	}
+	return text[:start] + result + text[start+len(core):]
+}
+
+func renderHTML(text string, styles []schemes.MarkupType, link schemes.MarkUp) string {
+	result := html.EscapeString(text)
+	if text == "" {
+		return result
+	}
+	for _, style := range styles {
+		if tag, ok := htmlTags[style]; ok {
+			result = "<" + tag + ">" + result + "</" + tag + ">"
+		}
+	}
+	switch link.Type {
+	case schemes.MarkupLink:
+		result = `<a href="` + html.EscapeString(link.Url) + `">` + result + "</a>"
+	case schemes.MarkupUser:
+		result = `<a href="` + userURL(link.UserId) + `">` + result + "</a>"
+	}
+	return result
+}
+
+func userURL(userID int64) string {
+	return "max://user/" + strconv.FormatInt(userID, 10)
+}
+
+// utf16Len returns length of text in UTF-16 code units
+func utf16Len(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
@@ -0,0 +1,107 @@
+package maxbot
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextBuilder(t *testing.T) {
+	build := func(format schemes.TextFormat) *TextBuilder {
+		return NewTextBuilder(format).
+			Text("Привет, ").
+			Mention("Алиса", 42).
+			Text("! 👍 ").
+			Bold("2*2").
+			Text(" ").
+			Link("<docs>", "https://dev.max.ru/a(b)", schemes.MarkupEmphasized)
+	}
+
+	tests := []struct {
+		name    string
+		format  schemes.TextFormat
+		want    string
+		markups []schemes.MarkUp
+	}{
+		{
+			name:   "markdown",
+			format: schemes.FormatMarkdown,
+			want:   `Привет, [Алиса](max://user/42)! 👍 **2\*2** [_<docs>_](https://dev.max.ru/a%28b%29)`,
+		},
+		{
+			name:   "html",
+			format: schemes.FormatHTML,
+			want:   `Привет, <a href="max://user/42">Алиса</a>! 👍 <b>2*2</b> <a href="https://dev.max.ru/a(b)"><i>&lt;docs&gt;</i></a>`,
+		},
+		{
+			name: "markup entities",
+			want: "Привет, Алиса! 👍 2*2 <docs>",
+			markups: []schemes.MarkUp{
+				{From: 8, Length: 5, Type: schemes.MarkupUser, UserId: 42},
+				{From: 18, Length: 3, Type: schemes.MarkupStrong},
+				{From: 22, Length: 6, Type: schemes.MarkupEmphasized},
+				{From: 22, Length: 6, Type: schemes.MarkupLink, Url: "https://dev.max.ru/a(b)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMessage().SetRichText(build(tt.format))
+			require.Equal(t, tt.want, m.message.Text)
+			require.Equal(t, string(tt.format), m.message.Format)
+			require.Equal(t, tt.markups, m.message.Markups)
+		})
+	}
+}
+
+func TestTextBuilderMarkdownWhitespace(t *testing.T) {
+	require.Equal(t, "a **b c** _d_\n ",
+		NewTextBuilder(schemes.FormatMarkdown).Text("a").Bold(" b c ").Italic("d\n").Strikethrough(" ").String())
+}
+
+func TestNewTextBuilderFromBody(t *testing.T) {
+	var body schemes.MessageBody
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"mid": "mid1",
+		"text": "Hi Bob, see docs 👍 now",
+		"markup": [
+			{"type": "user_mention", "from": 3, "length": 3, "user_id": 7},
+			{"type": "strong", "from": 8, "length": 8},
+			{"type": "link", "from": 12, "length": 4, "url": "https://dev.max.ru"},
+			{"type": "unknown", "from": 0, "length": 2}
+		]
+	}`), &body))
+
+	require.Len(t, body.Markup, 4)
+	require.Equal(t, []*schemes.UserMentionMarkup{{
+		MarkupElement: schemes.MarkupElement{Type: schemes.MarkupUser, From: 3, Length: 3},
+		UserId:        7,
+	}}, body.Mentions())
+	require.Len(t, body.Links(), 1)
+	require.Equal(t, "docs", body.MarkupText(body.Links()[0]))
+	require.IsType(t, &schemes.MarkupElement{}, body.Markup[3])
+
+	require.Equal(t,
+		`Hi [Bob](max://user/7), **see** [**docs**](https://dev.max.ru) 👍 now`,
+		NewTextBuilderFromBody(schemes.FormatMarkdown, body).String())
+	require.Equal(t,
+		`Hi <a href="max://user/7">Bob</a>, <b>see </b><a href="https://dev.max.ru"><b>docs</b></a> 👍 now`,
+		NewTextBuilderFromBody(schemes.FormatHTML, body).String())
+}
+
+func TestTextBuilderUnsupportedStyle(t *testing.T) {
+	require.Equal(t, "Title **body**", NewTextBuilder(schemes.FormatMarkdown).Heading("Title").Text(" ").Bold("body").String())
+	require.Equal(t, `<a href="https://dev.max.ru">docs</a>`,
+		NewTextBuilder(schemes.FormatHTML).Link("docs", "https://dev.max.ru", schemes.MarkupHighlighted).String())
+
+	b := NewTextBuilder("").Heading("Title").Text(" body")
+	require.Equal(t, []schemes.MarkUp{{From: 0, Length: 5, Type: schemes.MarkupHeading}}, b.Markups())
+
+	body := schemes.MessageBody{Text: "Title body", Markup: []schemes.MarkupElementInterface{
+		&schemes.MarkupElement{Type: schemes.MarkupHeading, From: 0, Length: 5},
+	}}
+	require.Equal(t, "Title body", NewTextBuilderFromBody(schemes.FormatMarkdown, body).String())
+}
@@ -0,0 +1,193 @@
+package maxbot
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+// UploadCacheKey identifies uploaded content by its SHA-256 hash and upload type
+type UploadCacheKey struct {
+	Hash string             // Hex encoded SHA-256 of content
+	Type schemes.UploadType // Upload type
+}
+
+// String returns key as string usable as file name
+func (k UploadCacheKey) String() string {
+	return k.Hash + "-" + string(k.Type)
+}
+
+// UploadCache stores tokens of uploaded files, so the same content is uploaded only once.
+// Implementations must be safe for concurrent use
+type UploadCache interface {
+	Get(key UploadCacheKey) (*UploadResult, bool)
+	Put(key UploadCacheKey, result *UploadResult) error
+}
+
+// MemoryUploadCache is in-memory UploadCache which evicts least recently used entries
+type MemoryUploadCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[UploadCacheKey]*list.Element
+}
+
+type memoryUploadCacheEntry struct {
+	key    UploadCacheKey
+	result *UploadResult
+}
+
+// NewMemoryUploadCache returns in-memory cache keeping at most capacity entries
+func NewMemoryUploadCache(capacity int) *MemoryUploadCache {
+	return &MemoryUploadCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[UploadCacheKey]*list.Element),
+	}
+}
+
+func (c *MemoryUploadCache) Get(key UploadCacheKey) (*UploadResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memoryUploadCacheEntry).result, true
+}
+
+func (c *MemoryUploadCache) Put(key UploadCacheKey, result *UploadResult) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryUploadCacheEntry).result = result
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryUploadCacheEntry{key: key, result: result})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryUploadCacheEntry).key)
+	}
+	return nil
+}
+
+// FileUploadCache is UploadCache storing every entry as JSON file in directory, so tokens survive restarts
+type FileUploadCache struct {
+	dir string
+}
+
+// NewFileUploadCache returns cache storing entries in dir. Directory is created if it does not exist
+func NewFileUploadCache(dir string) (*FileUploadCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileUploadCache{dir: dir}, nil
+}
+
+func (c *FileUploadCache) Get(key UploadCacheKey) (*UploadResult, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	result := new(UploadResult)
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+// Put writes entry to temporary file and renames it, so concurrent readers never see partial entry
+func (c *FileUploadCache) Put(key UploadCacheKey, result *UploadResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &SerializationError{Op: "marshal", Type: "upload cache entry", Err: err}
+	}
+
+	tmp, err := os.CreateTemp(c.dir, key.String()+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		return errors.Join(err, os.Remove(tmp.Name()))
+	}
+	return nil
+}
+
+func (c *FileUploadCache) path(key UploadCacheKey) string {
+	return filepath.Join(c.dir, key.String()+".json")
+}
+
+// spoolMemoryLimit is size of content kept in memory by spool, larger content is written to temporary file
+const spoolMemoryLimit = 8 << 20
+
+// spool copies content of reader to memory or temporary file, so it can be hashed before upload.
+// Returned cleanup removes temporary file
+func spool(reader io.Reader) (io.ReadSeeker, func(), error) {
+	head, err := io.ReadAll(io.LimitReader(reader, spoolMemoryLimit+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(head) <= spoolMemoryLimit {
+		return bytes.NewReader(head), func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "maxbot-upload-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		if err := file.Close(); err != nil {
+			log.Println(err)
+		}
+		if err := os.Remove(file.Name()); err != nil {
+			log.Println(err)
+		}
+	}
+	if _, err := io.Copy(file, io.MultiReader(bytes.NewReader(head), reader)); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return file, cleanup, nil
+}
+
+// hashContent returns hex encoded SHA-256 of content left in reader and rewinds reader back
+func hashContent(reader io.ReadSeeker) (string, error) {
+	offset, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", err
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,108 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryUploadCache(t *testing.T) {
+	cache := NewMemoryUploadCache(2)
+	a := UploadCacheKey{Hash: "a", Type: schemes.PHOTO}
+	b := UploadCacheKey{Hash: "b", Type: schemes.PHOTO}
+	c := UploadCacheKey{Hash: "c", Type: schemes.PHOTO}
+
+	require.NoError(t, cache.Put(a, &UploadResult{Type: schemes.PHOTO}))
+	require.NoError(t, cache.Put(b, &UploadResult{Type: schemes.PHOTO}))
+	_, ok := cache.Get(a)
+	require.True(t, ok)
+	require.NoError(t, cache.Put(c, &UploadResult{Type: schemes.PHOTO}))
+
+	_, ok = cache.Get(b)
+	require.False(t, ok, "least recently used entry is evicted")
+	_, ok = cache.Get(a)
+	require.True(t, ok)
+	_, ok = cache.Get(UploadCacheKey{Hash: "a", Type: schemes.FILE})
+	require.False(t, ok, "upload type is part of key")
+}
+
+func TestFileUploadCache(t *testing.T) {
+	cache, err := NewFileUploadCache(t.TempDir())
+	require.NoError(t, err)
+
+	key := UploadCacheKey{Hash: "abc", Type: schemes.FILE}
+	_, ok := cache.Get(key)
+	require.False(t, ok)
+
+	want := &UploadResult{Type: schemes.FILE, Filename: "report.pdf", Info: &schemes.UploadedInfo{FileID: 1, Token: "t"}}
+	require.NoError(t, cache.Put(key, want))
+	got, ok := cache.Get(key)
+	require.True(t, ok)
+	require.Equal(t, want, got)
+}
+
+func TestUploadWithCache(t *testing.T) {
+	uploads := 0
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+	api.Uploads.SetCache(NewMemoryUploadCache(10))
+
+	for i := 0; i < 2; i++ {
+		photo, err := api.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader("logo"))
+		require.NoError(t, err)
+		require.Equal(t, "photo-token", photo.Photos["p1"].Token)
+	}
+	require.Equal(t, 1, uploads)
+
+	_, err := api.Uploads.UploadMediaFromReader(context.Background(), schemes.FILE, strings.NewReader("logo"))
+	require.NoError(t, err)
+	require.Equal(t, 2, uploads, "other upload type is uploaded again")
+
+	_, err = api.Uploads.UploadPhotoFromReader(context.Background(), bytes.NewBufferString("new logo"))
+	require.NoError(t, err)
+	_, err = api.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader("new logo"))
+	require.NoError(t, err)
+	require.Equal(t, 3, uploads, "content of non-seekable reader is spooled and hashed before upload")
+	_, err = api.Uploads.UploadPhotoFromReader(context.Background(), bytes.NewBufferString("new logo"))
+	require.NoError(t, err)
+	require.Equal(t, 3, uploads)
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("remote logo"))
+	}))
+	t.Cleanup(remote.Close)
+	for i := 0; i < 2; i++ {
+		photo, err := api.Uploads.UploadPhotoFromUrl(context.Background(), remote.URL+"/logo.png")
+		require.NoError(t, err)
+		require.Equal(t, "photo-token", photo.Photos["p1"].Token)
+	}
+	require.Equal(t, 4, uploads, "second upload from URL is served from cache")
+}
+
+func TestSpool(t *testing.T) {
+	content := strings.Repeat("x", spoolMemoryLimit+10)
+	reader, cleanup, err := spool(iotest.OneByteReader(strings.NewReader(content)))
+	require.NoError(t, err)
+	file, ok := reader.(*os.File)
+	require.True(t, ok, "large content is spooled to temporary file")
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, content, string(data))
+
+	cleanup()
+	_, err = os.Stat(file.Name())
+	require.True(t, os.IsNotExist(err))
+}
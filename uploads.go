@@ -5,84 +5,362 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rectid/max-bot-api-client-go/schemes"
 )
 
+// ProgressFunc is called while file is sent to upload server with number of bytes sent and total size of file.
+// Total is -1 if size of file is unknown
+type ProgressFunc func(sent int64, total int64)
+
+// UploadOptions configures single upload
+type UploadOptions struct {
+	Filename    string        // Name of uploaded file. Default is "file"
+	ContentType string        // MIME type of uploaded file. Detected by extension of Filename if empty
+	Progress    ProgressFunc  // Reports upload progress
+	Timeout     time.Duration // Limits duration of whole upload. Zero means upload is limited only by context
+}
+
+// withFile returns options with filename and content type set if they are empty
+func (o UploadOptions) withFile(filename string, contentType string) UploadOptions {
+	if o.Filename == "" {
+		o.Filename = filename
+	}
+	if o.ContentType == "" {
+		o.ContentType = contentType
+	}
+	return o
+}
+
+// withDefaults returns options with filename and content type ready for upload request
+func (o UploadOptions) withDefaults() UploadOptions {
+	o = o.withFile("file", mime.TypeByExtension(filepath.Ext(o.Filename)))
+	if o.ContentType == "" {
+		o.ContentType = "application/octet-stream"
+	}
+	return o
+}
+
+// uploadOptions returns first of optional upload options
+func uploadOptions(opts []UploadOptions) UploadOptions {
+	if len(opts) == 0 {
+		return UploadOptions{}
+	}
+	return opts[0]
+}
+
+// progressReader reports number of bytes read from reader
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 && r.progress != nil {
+		r.sent += int64(n)
+		r.progress(r.sent, r.total)
+	}
+	return n, err
+}
+
+// readSeekerAt is reader which can be uploaded in chunks
+type readSeekerAt interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+// Delays between retries of failed chunk, doubled after every attempt
+const (
+	defaultChunkRetryDelay = 500 * time.Millisecond
+	maxChunkRetryDelay     = 5 * time.Second
+)
+
 type uploads struct {
-	client *client
+	client     *client
+	chunkSize  int64
+	retryDelay time.Duration
+	cache      UploadCache
+
+	maxRemoteSize   int64
+	publicTransport http.RoundTripper
+	remoteTimeout   time.Duration
+	imageProcessor  ImageProcessor
 }
 
 func newUploads(client *client) *uploads {
-	return &uploads{client: client}
+	return &uploads{client: client, retryDelay: defaultChunkRetryDelay, maxRemoteSize: DefaultMaxRemoteSize}
+}
+
+// SetChunkSize enables resumable upload of files larger than size bytes in chunks of size bytes.
+// Every chunk is sent with Content-Range header and is retried on failure.
+// Chunked upload is used only for readers with known size implementing io.ReaderAt and io.Seeker, like *os.File.
+// Zero size disables chunked upload
+func (a *uploads) SetChunkSize(size int64) {
+	a.chunkSize = size
+}
+
+// SetCache enables reuse of tokens of already uploaded content. Nil cache disables caching
+func (a *uploads) SetCache(cache UploadCache) {
+	a.cache = cache
 }
 
 // UploadMedia uploads file to Max server
-func (a *uploads) UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string) (*schemes.UploadedInfo, error) {
+func (a *uploads) UploadMediaFromFile(ctx context.Context, uploadType schemes.UploadType, filename string, opts ...UploadOptions) (*schemes.UploadedInfo, error) {
 	fh, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer fh.Close()
-	return a.UploadMediaFromReader(ctx, uploadType, fh)
+	return a.UploadMediaFromReader(ctx, uploadType, fh, uploadOptions(opts).withFile(filepath.Base(filename), ""))
 }
 
 // UploadMediaFromUrl uploads file from remote server to Max server
-func (a *uploads) UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL) (*schemes.UploadedInfo, error) {
-	respFile, err := http.Get(u.String())
+func (a *uploads) UploadMediaFromUrl(ctx context.Context, uploadType schemes.UploadType, u url.URL, opts ...UploadOptions) (*schemes.UploadedInfo, error) {
+	respFile, err := a.fetch(ctx, u.String())
 	if err != nil {
 		return nil, err
 	}
 	defer respFile.Body.Close()
-	return a.UploadMediaFromReader(ctx, uploadType, respFile.Body)
+	return a.UploadMediaFromReader(ctx, uploadType, respFile.Body, remoteFileOptions(respFile, uploadOptions(opts)))
 }
 
-func (a *uploads) UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader) (*schemes.UploadedInfo, error) {
+func (a *uploads) UploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, opts ...UploadOptions) (*schemes.UploadedInfo, error) {
 	result := new(schemes.UploadedInfo)
-	return result, a.uploadMediaFromReader(ctx, uploadType, reader, result)
+	return result, a.uploadMediaFromReader(ctx, uploadType, reader, result, uploadOptions(opts))
 }
 
 // UploadPhotoFromFile uploads photos to Max server
-func (a *uploads) UploadPhotoFromFile(ctx context.Context, fileName string) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromFile(ctx context.Context, fileName string, opts ...UploadOptions) (*schemes.PhotoTokens, error) {
 	fh, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
 	}
 	defer fh.Close()
 	result := new(schemes.PhotoTokens)
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, fh, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, fh, result, uploadOptions(opts).withFile(filepath.Base(fileName), ""))
 }
 
 // UploadPhotoFromFile uploads photos to Max server
-func (a *uploads) UploadPhotoFromBase64String(ctx context.Context, code string) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromBase64String(ctx context.Context, code string, opts ...UploadOptions) (*schemes.PhotoTokens, error) {
 	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(code))
 	result := new(schemes.PhotoTokens)
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, decoder, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, decoder, result, uploadOptions(opts))
 }
 
 // UploadPhotoFromUrl uploads photo from remote server to Max server
-func (a *uploads) UploadPhotoFromUrl(ctx context.Context, url string) (*schemes.PhotoTokens, error) {
-	respFile, err := http.Get(url)
+func (a *uploads) UploadPhotoFromUrl(ctx context.Context, url string, opts ...UploadOptions) (*schemes.PhotoTokens, error) {
+	respFile, err := a.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	defer respFile.Body.Close()
 	result := new(schemes.PhotoTokens)
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, respFile.Body, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, respFile.Body, result, remoteFileOptions(respFile, uploadOptions(opts)))
 }
 
 // UploadPhotoFromReader uploads photo from reader
-func (a *uploads) UploadPhotoFromReader(ctx context.Context, reader io.Reader) (*schemes.PhotoTokens, error) {
+func (a *uploads) UploadPhotoFromReader(ctx context.Context, reader io.Reader, opts ...UploadOptions) (*schemes.PhotoTokens, error) {
 	result := new(schemes.PhotoTokens)
-	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, result)
+	return result, a.uploadMediaFromReader(ctx, schemes.PHOTO, reader, result, uploadOptions(opts))
+}
+
+// UploadResult is result of upload with automatically chosen upload type
+type UploadResult struct {
+	Type        schemes.UploadType    `json:"type"`
+	Filename    string                `json:"filename,omitempty"`
+	ContentType string                `json:"content_type,omitempty"`
+	Photo       *schemes.PhotoTokens  `json:"photo,omitempty"` // Set for photo uploads
+	Info        *schemes.UploadedInfo `json:"info,omitempty"`  // Set for video, audio and file uploads
+}
+
+// newUploadResult returns upload result for result of uploadMediaFromReader
+func newUploadResult(uploadType schemes.UploadType, result interface{}, opts UploadOptions) *UploadResult {
+	upload := &UploadResult{Type: uploadType, Filename: opts.Filename, ContentType: opts.ContentType}
+	switch result := result.(type) {
+	case *schemes.PhotoTokens:
+		upload.Photo = result
+	case *schemes.UploadedInfo:
+		upload.Info = result
+	}
+	return upload
+}
+
+// fill copies tokens of upload into result of uploadMediaFromReader
+func (r *UploadResult) fill(result interface{}) bool {
+	switch result := result.(type) {
+	case *schemes.PhotoTokens:
+		if r.Photo == nil {
+			return false
+		}
+		*result = *r.Photo
+	case *schemes.UploadedInfo:
+		if r.Info == nil {
+			return false
+		}
+		*result = *r.Info
+	default:
+		return false
+	}
+	return true
+}
+
+// UploadFile uploads file choosing upload type by its content type.
+// Content type is taken from options, detected by extension of filename or by content of file
+func (a *uploads) UploadFile(ctx context.Context, reader io.Reader, opts UploadOptions) (*UploadResult, error) {
+	if opts.ContentType == "" {
+		opts.ContentType = mime.TypeByExtension(filepath.Ext(opts.Filename))
+	}
+	if opts.ContentType == "" {
+		var err error
+		if opts.ContentType, reader, err = sniffContentType(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	uploadType := UploadTypeFor(opts.ContentType)
+	reader, opts, err := a.processImage(uploadType, reader, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UploadResult{Type: uploadType, Filename: opts.Filename, ContentType: opts.ContentType}
+	if result.Type == schemes.PHOTO {
+		result.Photo = new(schemes.PhotoTokens)
+		return result, a.uploadCached(ctx, result.Type, reader, result.Photo, opts)
+	}
+	result.Info = new(schemes.UploadedInfo)
+	return result, a.uploadCached(ctx, result.Type, reader, result.Info, opts)
+}
+
+// UploadFileFromPath uploads file from disk choosing upload type by its content type
+func (a *uploads) UploadFileFromPath(ctx context.Context, filename string, opts ...UploadOptions) (*UploadResult, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return a.UploadFile(ctx, fh, uploadOptions(opts).withFile(filepath.Base(filename), ""))
+}
+
+// UploadItem is file uploaded by UploadMany
+type UploadItem struct {
+	Reader  io.Reader     // Content of file. If nil, file at Path is uploaded
+	Path    string        // Path of file on disk
+	Options UploadOptions // Options of upload
+}
+
+// UploadMany uploads files concurrently, at most parallelism files at once, choosing upload type of every file like UploadFile.
+// Results are returned in order of items. The first failed upload cancels the rest, all upload errors are joined
+func (a *uploads) UploadMany(ctx context.Context, items []UploadItem, parallelism int) ([]*UploadResult, error) {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*UploadResult, len(items))
+	errs := make([]error, len(items))
+	semaphore := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			if item.Reader != nil {
+				results[i], errs[i] = a.UploadFile(ctx, item.Reader, item.Options)
+			} else {
+				results[i], errs[i] = a.UploadFileFromPath(ctx, item.Path, item.Options)
+			}
+			if errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// UploadTypeFor returns upload type for MIME type: photo for images, video, audio and file for the rest
+func UploadTypeFor(contentType string) schemes.UploadType {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return schemes.FILE
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/") && mediaType != "image/svg+xml":
+		return schemes.PHOTO
+	case strings.HasPrefix(mediaType, "video/"):
+		return schemes.VIDEO
+	case strings.HasPrefix(mediaType, "audio/"):
+		return schemes.AUDIO
+	default:
+		return schemes.FILE
+	}
+}
+
+// sniffContentType detects content type by first bytes of reader.
+// Returns reader which still yields the whole content
+func sniffContentType(reader io.Reader) (string, io.Reader, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(int64(-n), io.SeekCurrent); err == nil {
+			return contentType, reader, nil
+		}
+	}
+	return contentType, io.MultiReader(bytes.NewReader(head), reader), nil
+}
+
+// remoteFileOptions returns options with filename and content type of remote file
+func remoteFileOptions(resp *http.Response, opts UploadOptions) UploadOptions {
+	filename := path.Base(resp.Request.URL.Path)
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		filename = params["filename"]
+	}
+	if filename == "/" || filename == "." {
+		filename = ""
+	}
+	return opts.withFile(filename, resp.Header.Get("Content-Type"))
+}
+
+// httpClient returns copy of configured client without its timeout for uploads,
+// so duration of upload is limited only by context and UploadOptions.Timeout
+func (a *uploads) httpClient() *http.Client {
+	client := *a.client.httpClient
+	client.Timeout = 0
+	return &client
 }
 
 func (a *uploads) getUploadURL(ctx context.Context, uploadType schemes.UploadType) (*schemes.UploadEndpoint, error) {
@@ -101,33 +379,89 @@ func (a *uploads) getUploadURL(ctx context.Context, uploadType schemes.UploadTyp
 	return result, json.NewDecoder(body).Decode(result)
 }
 
-func (a *uploads) uploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, result interface{}) error {
-	endpoint, err := a.getUploadURL(ctx, uploadType)
+// uploadMediaFromReader preprocesses photos and uploads content
+func (a *uploads) uploadMediaFromReader(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, result interface{}, opts UploadOptions) error {
+	reader, opts, err := a.processImage(uploadType, reader, opts)
 	if err != nil {
 		return err
 	}
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
-	fileWriter, err := bodyWriter.CreateFormFile("data", "file")
-	if err != nil {
-		return err
+	return a.uploadCached(ctx, uploadType, reader, result, opts)
+}
+
+// processImage applies image processor to photos
+func (a *uploads) processImage(uploadType schemes.UploadType, reader io.Reader, opts UploadOptions) (io.Reader, UploadOptions, error) {
+	if uploadType != schemes.PHOTO || a.imageProcessor == nil {
+		return reader, opts, nil
+	}
+	return a.imageProcessor(reader, opts)
+}
+
+// uploadCached uploads content unless its tokens are found in cache.
+// Readers which can not seek, like remote files, are spooled by spool to be hashed before upload
+func (a *uploads) uploadCached(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, result interface{}, opts UploadOptions) error {
+	if a.cache == nil {
+		return a.upload(ctx, uploadType, reader, result, opts)
 	}
-	_, err = io.Copy(fileWriter, reader)
+
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		spooled, cleanup, err := spool(reader)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		seeker = spooled
+	}
+	contentHash, err := hashContent(seeker)
 	if err != nil {
 		return err
 	}
+	key := UploadCacheKey{Hash: contentHash, Type: uploadType}
+	if cached, ok := a.cache.Get(key); ok && cached.fill(result) {
+		return nil
+	}
 
-	if err := bodyWriter.Close(); err != nil {
+	if err := a.upload(ctx, uploadType, seeker, result, opts); err != nil {
 		return err
 	}
-	contentType := bodyWriter.FormDataContentType()
-	if err := bodyWriter.Close(); err != nil {
+	if err := a.cache.Put(key, newUploadResult(uploadType, result, opts)); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+func (a *uploads) upload(ctx context.Context, uploadType schemes.UploadType, reader io.Reader, result interface{}, opts UploadOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	timedOut := func(err error) error {
+		if opts.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return &TimeoutError{Op: "upload", Reason: fmt.Sprintf("upload timeout exceeded (%v)", opts.Timeout)}
+		}
 		return err
 	}
 
-	resp, err := http.Post(endpoint.Url, contentType, bodyBuf)
+	opts = opts.withDefaults()
+	endpoint, err := a.getUploadURL(ctx, uploadType)
 	if err != nil {
-		return err
+		return timedOut(err)
+	}
+
+	size := readerSize(reader)
+	var resp *http.Response
+	if ra, ok := reader.(readSeekerAt); ok && a.chunkSize > 0 && size > a.chunkSize {
+		resp, err = a.uploadChunks(ctx, endpoint.Url, ra, size, opts)
+	} else {
+		resp, err = a.uploadMultipart(ctx, endpoint.Url, &progressReader{reader: reader, total: size, progress: opts.Progress}, size, opts)
+	}
+	if err != nil {
+		var uploadErr *UploadError
+		if errors.As(err, &uploadErr) {
+			uploadErr.Type = uploadType
+		}
+		return timedOut(err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -135,22 +469,210 @@ func (a *uploads) uploadMediaFromReader(ctx context.Context, uploadType schemes.
 		}
 	}()
 
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return timedOut(&NetworkError{Op: "read upload response", Err: err})
+	}
+	return parseUploadResponse(uploadType, endpoint, resp.StatusCode, data, result)
+}
+
+// uploadResponse is response of upload server. Fields are filled depending on upload type
+type uploadResponse struct {
+	FileID      int64                         `json:"file_id"`
+	FileIDCamel int64                         `json:"fileId"`
+	Token       string                        `json:"token"`
+	Photos      map[string]schemes.PhotoToken `json:"photos"`
+	Code        string                        `json:"code"`
+	Message     string                        `json:"message"`
+	ErrorText   string                        `json:"error"`
+	ErrorMsg    string                        `json:"error_msg"`
+}
+
+func (r uploadResponse) errorMessage() string {
+	for _, message := range []string{r.Message, r.ErrorMsg, r.ErrorText} {
+		if message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// parseUploadResponse fills result with tokens from response of upload server.
+// Video and audio tokens are issued with upload url, so they are taken from endpoint if response has none
+func parseUploadResponse(uploadType schemes.UploadType, endpoint *schemes.UploadEndpoint, status int, data []byte, result interface{}) error {
+	response := uploadResponse{}
+	decodeErr := json.Unmarshal(data, &response)
+
+	if status < 200 || status > 299 {
+		message := response.errorMessage()
+		if message == "" {
+			message = http.StatusText(status)
+		}
+		return &UploadError{Type: uploadType, StatusCode: status, Code: response.Code, Message: message}
+	}
+	if decodeErr == nil && response.errorMessage() != "" && response.Token == "" && len(response.Photos) == 0 {
+		return &UploadError{Type: uploadType, StatusCode: status, Code: response.Code, Message: response.errorMessage()}
+	}
+
 	switch result := result.(type) {
+	case *schemes.PhotoTokens:
+		if decodeErr != nil {
+			return &SerializationError{Op: "unmarshal", Type: "upload response", Err: decodeErr}
+		}
+		result.Photos = response.Photos
+		result.Token = response.Token
+		if len(result.Photos) == 0 && result.Token == "" {
+			return &UploadError{Type: uploadType, StatusCode: status, Message: "response has no photo tokens"}
+		}
 	case *schemes.UploadedInfo:
-		// Read response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Println(err)
-			return err
+		if decodeErr != nil && endpoint.Token == "" {
+			return &SerializationError{Op: "unmarshal", Type: "upload response", Err: decodeErr}
+		}
+		result.FileID = max(response.FileID, response.FileIDCamel)
+		result.Token = response.Token
+		if result.Token == "" {
+			result.Token = endpoint.Token
+		}
+		if result.Token == "" {
+			return &UploadError{Type: uploadType, StatusCode: status, Message: "response has no token"}
 		}
-		result.Token = endpoint.Token
-		// Print response body as string
-		log.Println(string(body))
 	default:
-		if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return err
+		if decodeErr != nil {
+			return &SerializationError{Op: "unmarshal", Type: "upload response", Err: decodeErr}
+		}
+		if err := json.Unmarshal(data, result); err != nil {
+			return &SerializationError{Op: "unmarshal", Type: "upload response", Err: err}
 		}
 	}
 
 	return nil
 }
+
+// uploadMultipart streams reader as multipart form without buffering it in memory.
+// Content-Length is set when size of reader is known
+func (a *uploads) uploadMultipart(ctx context.Context, uploadURL string, reader io.Reader, size int64, opts UploadOptions) (*http.Response, error) {
+	form := &bytes.Buffer{}
+	bodyWriter := multipart.NewWriter(form)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "data", "filename": opts.Filename}))
+	header.Set("Content-Type", opts.ContentType)
+	if _, err := bodyWriter.CreatePart(header); err != nil {
+		return nil, err
+	}
+	head := form.String()
+	form.Reset()
+	if err := bodyWriter.Close(); err != nil {
+		return nil, err
+	}
+	tail := form.String()
+
+	body := io.MultiReader(strings.NewReader(head), reader, strings.NewReader(tail))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", bodyWriter.FormDataContentType())
+	if size >= 0 {
+		req.ContentLength = int64(len(head)) + size + int64(len(tail))
+	}
+	return a.httpClient().Do(req)
+}
+
+// uploadChunks uploads reader in chunks with Content-Range header. Failed chunks are retried.
+// Returns response for the last chunk
+func (a *uploads) uploadChunks(ctx context.Context, uploadURL string, reader readSeekerAt, size int64, opts UploadOptions) (*http.Response, error) {
+	base, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	for offset := int64(0); ; {
+		end := min(offset+a.chunkSize, size)
+		var resp *http.Response
+		delay := a.retryDelay
+		for attempt := 0; attempt < maxRetries && ctx.Err() == nil; attempt++ {
+			chunk := &progressReader{reader: io.NewSectionReader(reader, base+offset, end-offset), sent: offset, total: size, progress: opts.Progress}
+			resp, err = a.uploadChunk(ctx, uploadURL, chunk, offset, end, size, opts)
+			if err == nil || !retryableChunkError(err) || attempt == maxRetries-1 {
+				break
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, maxChunkRetryDelay)
+		}
+		if ctx.Err() != nil {
+			if resp != nil {
+				if err := resp.Body.Close(); err != nil {
+					log.Println(err)
+				}
+			}
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if end == size {
+			return resp, nil
+		}
+		if err := resp.Body.Close(); err != nil {
+			log.Println(err)
+		}
+		offset = end
+	}
+}
+
+// retryableChunkError reports whether chunk upload may succeed on retry. Chunks rejected by server with 4xx status fail again
+func retryableChunkError(err error) bool {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		return uploadErr.StatusCode >= 500
+	}
+	var netErr *NetworkError
+	return errors.As(err, &netErr)
+}
+
+func (a *uploads) uploadChunk(ctx context.Context, uploadURL string, chunk io.Reader, from int64, to int64, size int64, opts UploadOptions) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, chunk)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = to - from
+	req.Header.Set("Content-Type", opts.ContentType)
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": opts.Filename}))
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", from, to-1, size))
+
+	resp, err := a.httpClient().Do(req)
+	if err != nil {
+		return nil, &NetworkError{Op: "upload chunk", Err: err}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if err := resp.Body.Close(); err != nil {
+			log.Println(err)
+		}
+		return nil, &UploadError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("chunk %d-%d rejected: %s", from, to-1, http.StatusText(resp.StatusCode))}
+	}
+	return resp, nil
+}
+
+// readerSize returns number of bytes left in reader or -1 if it is unknown
+func readerSize(reader io.Reader) int64 {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len())
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return info.Size() - offset
+	default:
+		return -1
+	}
+}
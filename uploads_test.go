@@ -0,0 +1,296 @@
+package maxbot
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+// newUploadTestApi returns api which upload endpoint is served by upload handler
+func newUploadTestApi(t *testing.T, upload http.HandlerFunc) *Api {
+	t.Helper()
+
+	var api *Api
+	api = newTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/uploads":
+			require.NoError(t, json.NewEncoder(w).Encode(schemes.UploadEndpoint{
+				Url:   api.client.baseURL.String() + "/upload",
+				Token: "endpoint-token",
+			}))
+		case "/upload":
+			upload(w, r)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	return api
+}
+
+func TestUploadStreaming(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Greater(t, r.ContentLength, int64(len(content)))
+		file, _, err := r.FormFile("data")
+		require.NoError(t, err)
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+
+	photo, err := api.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader(content))
+	require.NoError(t, err)
+	require.Equal(t, "photo-token", photo.Photos["p1"].Token)
+}
+
+func TestUploadChunks(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []string
+	var received strings.Builder
+	failed := false
+	var retried []time.Time
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		contentRange := r.Header.Get("Content-Range")
+		if contentRange == "bytes 4-7/10" {
+			retried = append(retried, time.Now())
+		}
+		if contentRange == "bytes 4-7/10" && !failed {
+			failed = true
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		ranges = append(ranges, contentRange)
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received.Write(data)
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+	api.Uploads.SetChunkSize(4)
+	api.Uploads.retryDelay = 20 * time.Millisecond
+
+	photo, err := api.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader("0123456789"))
+	require.NoError(t, err)
+	require.Equal(t, "photo-token", photo.Photos["p1"].Token)
+	require.Equal(t, []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"}, ranges)
+	require.Equal(t, "0123456789", received.String())
+	require.Len(t, retried, 2)
+	require.GreaterOrEqual(t, retried[1].Sub(retried[0]), 20*time.Millisecond, "failed chunk is retried after delay")
+
+	attempts := 0
+	rejecting := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	rejecting.Uploads.SetChunkSize(4)
+
+	_, err = rejecting.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader("0123456789"))
+	var uploadErr *UploadError
+	require.ErrorAs(t, err, &uploadErr)
+	require.Equal(t, http.StatusBadRequest, uploadErr.StatusCode)
+	require.Equal(t, 1, attempts)
+}
+
+func TestUploadProgressAndTimeout(t *testing.T) {
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+	})
+
+	var sent, total int64
+	_, err := api.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader(strings.Repeat("x", 100000)), UploadOptions{
+		Progress: func(s int64, t int64) { sent, total = s, t },
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100000), sent)
+	require.Equal(t, int64(100000), total)
+
+	stuck := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	})
+	_, err = stuck.Uploads.UploadPhotoFromReader(context.Background(), strings.NewReader("x"), UploadOptions{Timeout: 50 * time.Millisecond})
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+}
+
+func TestUploadFile(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 100)
+	tests := []struct {
+		name            string
+		reader          io.Reader
+		opts            UploadOptions
+		wantType        schemes.UploadType
+		wantFilename    string
+		wantContentType string
+	}{
+		{
+			name:            "by extension",
+			reader:          strings.NewReader("%PDF-1.4"),
+			opts:            UploadOptions{Filename: "report.pdf"},
+			wantType:        schemes.FILE,
+			wantFilename:    "report.pdf",
+			wantContentType: "application/pdf",
+		},
+		{
+			name:            "by content",
+			reader:          io.MultiReader(strings.NewReader(png)),
+			wantType:        schemes.PHOTO,
+			wantFilename:    "file",
+			wantContentType: "image/png",
+		},
+		{
+			name:            "explicit",
+			reader:          strings.NewReader("ID3"),
+			opts:            UploadOptions{Filename: "song", ContentType: "audio/mpeg"},
+			wantType:        schemes.AUDIO,
+			wantFilename:    "song",
+			wantContentType: "audio/mpeg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+				file, header, err := r.FormFile("data")
+				require.NoError(t, err)
+				require.Equal(t, tt.wantFilename, header.Filename)
+				require.Equal(t, tt.wantContentType, header.Header.Get("Content-Type"))
+				data, err := io.ReadAll(file)
+				require.NoError(t, err)
+				if tt.name == "by content" {
+					require.Equal(t, png, string(data))
+				}
+				_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"photo-token"}}}`))
+			})
+
+			result, err := api.Uploads.UploadFile(context.Background(), tt.reader, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantType, result.Type)
+			require.Equal(t, tt.wantType == schemes.PHOTO, result.Photo != nil)
+		})
+	}
+}
+
+func TestParseUploadResponse(t *testing.T) {
+	endpoint := &schemes.UploadEndpoint{Url: "https://upload", Token: "endpoint-token"}
+	tests := []struct {
+		name       string
+		uploadType schemes.UploadType
+		endpoint   *schemes.UploadEndpoint
+		status     int
+		body       string
+		result     interface{}
+		want       interface{}
+		wantErr    *UploadError
+	}{
+		{
+			name:       "photo sizes",
+			uploadType: schemes.PHOTO,
+			status:     http.StatusOK,
+			body:       `{"photos":{"s":{"token":"t1"},"m":{"token":"t2"}}}`,
+			result:     new(schemes.PhotoTokens),
+			want:       &schemes.PhotoTokens{Photos: map[string]schemes.PhotoToken{"s": {Token: "t1"}, "m": {Token: "t2"}}},
+		},
+		{
+			name:       "file",
+			uploadType: schemes.FILE,
+			endpoint:   &schemes.UploadEndpoint{Url: "https://upload"},
+			status:     http.StatusOK,
+			body:       `{"fileId":42,"token":"file-token"}`,
+			result:     new(schemes.UploadedInfo),
+			want:       &schemes.UploadedInfo{FileID: 42, Token: "file-token"},
+		},
+		{
+			name:       "video token from endpoint",
+			uploadType: schemes.VIDEO,
+			status:     http.StatusOK,
+			body:       `<retval>1</retval>`,
+			result:     new(schemes.UploadedInfo),
+			want:       &schemes.UploadedInfo{Token: "endpoint-token"},
+		},
+		{
+			name:       "server error",
+			uploadType: schemes.FILE,
+			status:     http.StatusBadRequest,
+			body:       `{"code":"file.too.big","message":"File is too big"}`,
+			result:     new(schemes.UploadedInfo),
+			wantErr:    &UploadError{Type: schemes.FILE, StatusCode: http.StatusBadRequest, Code: "file.too.big", Message: "File is too big"},
+		},
+		{
+			name:       "error in successful response",
+			uploadType: schemes.PHOTO,
+			status:     http.StatusOK,
+			body:       `{"error_msg":"Unsupported image"}`,
+			result:     new(schemes.PhotoTokens),
+			wantErr:    &UploadError{Type: schemes.PHOTO, StatusCode: http.StatusOK, Message: "Unsupported image"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := endpoint
+			if tt.endpoint != nil {
+				e = tt.endpoint
+			}
+			err := parseUploadResponse(tt.uploadType, e, tt.status, []byte(tt.body), tt.result)
+			if tt.wantErr != nil {
+				var uploadErr *UploadError
+				require.ErrorAs(t, err, &uploadErr)
+				require.Equal(t, tt.wantErr, uploadErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, tt.result)
+		})
+	}
+}
+
+func TestUploadMany(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	api := newUploadTestApi(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		_, header, err := r.FormFile("data")
+		require.NoError(t, err)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"photos":{"p1":{"token":"` + header.Filename + `"}}}`))
+	})
+
+	items := []UploadItem{
+		{Reader: strings.NewReader("a"), Options: UploadOptions{Filename: "1.jpg"}},
+		{Reader: strings.NewReader("b"), Options: UploadOptions{Filename: "2.mp4"}},
+		{Reader: strings.NewReader("c"), Options: UploadOptions{Filename: "3.png"}},
+		{Reader: strings.NewReader("d"), Options: UploadOptions{Filename: "4.jpg"}},
+	}
+	results, err := api.Uploads.UploadMany(context.Background(), items, 2)
+	require.NoError(t, err)
+	require.LessOrEqual(t, maxActive, 2)
+	require.Equal(t, "1.jpg", results[0].Photo.Photos["p1"].Token)
+	require.Equal(t, schemes.VIDEO, results[1].Type)
+	require.Equal(t, "3.png", results[2].Photo.Photos["p1"].Token)
+
+	message := NewMessage().SetChat(1).AddMediaGroup(results...)
+	require.NoError(t, message.Validate())
+
+	audio := &UploadResult{Type: schemes.AUDIO, Info: &schemes.UploadedInfo{Token: "audio"}}
+	var verr *ValidationError
+	require.ErrorAs(t, NewMessage().SetChat(1).AddMediaGroup(results[0], audio).Validate(), &verr)
+}
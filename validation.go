@@ -0,0 +1,140 @@
+package maxbot
+
+import (
+	"fmt"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+)
+
+// Limits of message contents from API schema
+const (
+	MaxButtonTextLength      = 128
+	MaxCallbackPayloadLength = 1024
+	MaxButtonURLLength       = 2048
+	MaxChatTitleLength       = 200
+	MaxChatDescriptionLength = 400
+	MaxStartPayloadLength    = 512
+	MaxKeyboardRows          = 30
+	MaxButtonsPerRow         = 7
+	MaxKeyboardButtons       = 210
+)
+
+// Validate checks message against constraints of API schema.
+// Returns *ValidationError listing every violated constraint or nil if message is valid
+func (m *Message) Validate() error {
+	verr := &ValidationError{}
+
+	if !m.vip && m.chatID == 0 && m.userID == 0 {
+		verr.add("recipient", "neither chat nor user is set")
+	}
+
+	body := m.message
+	hasForward := body.Link != nil && body.Link.Type == schemes.FORWARD
+	if body.Text == "" && len(body.Attachments) == 0 && !hasForward {
+		verr.add("text", "message has neither text nor attachments")
+	}
+	if length := utf16Len(body.Text); length > MaxTextLength {
+		verr.add("text", "length %d exceeds %d characters", length, MaxTextLength)
+	}
+
+	contents := 0
+	for _, attachment := range body.Attachments {
+		if !isKeyboardRequest(attachment) {
+			contents++
+		}
+	}
+	for i, attachment := range body.Attachments {
+		field := fmt.Sprintf("attachments[%d]", i)
+		switch a := attachment.(type) {
+		case *schemes.AudioAttachmentRequest:
+			validateOnlyAttachment(verr, field, a.Type, contents)
+		case *schemes.FileAttachmentRequest:
+			validateOnlyAttachment(verr, field, a.Type, contents)
+		case *schemes.StickerAttachmentRequest:
+			validateOnlyAttachment(verr, field, a.Type, contents)
+		case *schemes.ContactAttachmentRequest:
+			validateOnlyAttachment(verr, field, a.Type, contents)
+		case *schemes.InlineKeyboardAttachmentRequest:
+			validateKeyboard(verr, field, a.Payload.Buttons)
+		case *schemes.ReplyKeyboardAttachmentRequest:
+			validateKeyboard(verr, field, a.Buttons)
+		}
+	}
+
+	if len(verr.Violations) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// validateOnlyAttachment checks attachment which must be the only one in message. Keyboards are not counted
+func validateOnlyAttachment(verr *ValidationError, field string, attachmentType schemes.AttachmentType, contents int) {
+	if contents > 1 {
+		verr.add(field, "%s must be the only attachment in message", attachmentType)
+	}
+}
+
+func validateKeyboard[B schemes.ButtonInterface](verr *ValidationError, field string, buttons [][]B) {
+	if len(buttons) > MaxKeyboardRows {
+		verr.add(field+".buttons", "%d rows exceed %d", len(buttons), MaxKeyboardRows)
+	}
+
+	total := 0
+	for i, row := range buttons {
+		total += len(row)
+		if len(row) > MaxButtonsPerRow {
+			verr.add(fmt.Sprintf("%s.buttons[%d]", field, i), "%d buttons exceed %d per row", len(row), MaxButtonsPerRow)
+		}
+		for j, button := range row {
+			validateButton(verr, fmt.Sprintf("%s.buttons[%d][%d]", field, i, j), button)
+		}
+	}
+	if total > MaxKeyboardButtons {
+		verr.add(field+".buttons", "%d buttons exceed %d", total, MaxKeyboardButtons)
+	}
+}
+
+func validateButton(verr *ValidationError, field string, button schemes.ButtonInterface) {
+	if button.GetText() == "" {
+		verr.add(field+".text", "button text is empty")
+	}
+	validateLength(verr, field+".text", button.GetText(), MaxButtonTextLength)
+
+	switch b := button.(type) {
+	case schemes.CallbackButton:
+		validatePayload(verr, field+".payload", b.Payload)
+	case *schemes.CallbackButton:
+		validatePayload(verr, field+".payload", b.Payload)
+	case schemes.LinkButton:
+		validateLength(verr, field+".url", b.Url, MaxButtonURLLength)
+	case *schemes.LinkButton:
+		validateLength(verr, field+".url", b.Url, MaxButtonURLLength)
+	case schemes.ChatButton:
+		validateChatButton(verr, field, &b)
+	case *schemes.ChatButton:
+		validateChatButton(verr, field, b)
+	case schemes.ReplyButtonInterface:
+		validatePayload(verr, field+".payload", b.GetPayload())
+	}
+}
+
+func validateChatButton(verr *ValidationError, field string, b *schemes.ChatButton) {
+	if b.ChatTitle == "" {
+		verr.add(field+".chat_title", "chat title is empty")
+	}
+	validateLength(verr, field+".chat_title", b.ChatTitle, MaxChatTitleLength)
+	validateLength(verr, field+".chat_description", b.ChatDescription, MaxChatDescriptionLength)
+	validateLength(verr, field+".start_payload", b.StartPayload, MaxStartPayloadLength)
+}
+
+func validateLength(verr *ValidationError, field string, value string, limit int) {
+	if length := utf16Len(value); length > limit {
+		verr.add(field, "length %d exceeds %d characters", length, limit)
+	}
+}
+
+func validatePayload(verr *ValidationError, field string, value string) {
+	if len(value) > MaxCallbackPayloadLength {
+		verr.add(field, "length %d exceeds %d bytes", len(value), MaxCallbackPayloadLength)
+	}
+}
@@ -0,0 +1,92 @@
+package maxbot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rectid/max-bot-api-client-go/schemes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		message func() *Message
+		fields  []string
+	}{
+		{
+			name:    "valid",
+			message: func() *Message { return NewMessage().SetChat(1).SetText("hello") },
+		},
+		{
+			name:    "forward without text",
+			message: func() *Message { return NewMessage().SetUser(1).Forward("mid1") },
+		},
+		{
+			name:    "no recipient and no content",
+			message: func() *Message { return NewMessage() },
+			fields:  []string{"recipient", "text"},
+		},
+		{
+			name:    "text too long",
+			message: func() *Message { return NewMessage().SetChat(1).SetText(strings.Repeat("a", MaxTextLength+1)) },
+			fields:  []string{"text"},
+		},
+		{
+			name: "audio with photo",
+			message: func() *Message {
+				return NewMessage().SetChat(1).
+					AddPhoto(&schemes.PhotoTokens{}).
+					AddAudio(&schemes.UploadedInfo{Token: "t"}).
+					AddKeyboard(&Keyboard{})
+			},
+			fields: []string{"attachments[1]"},
+		},
+		{
+			name: "file with keyboard",
+			message: func() *Message {
+				k := &Keyboard{}
+				k.AddRow().AddCallback("OK", schemes.POSITIVE, "ok")
+				return NewMessage().SetChat(1).AddFile(&schemes.UploadedInfo{Token: "t"}).AddKeyboard(k)
+			},
+		},
+		{
+			name: "invalid buttons",
+			message: func() *Message {
+				k := &Keyboard{}
+				k.AddRow().
+					AddCallback(strings.Repeat("a", MaxButtonTextLength+1), schemes.DEFAULT, strings.Repeat("p", MaxCallbackPayloadLength+1)).
+					AddLink("", schemes.DEFAULT, "https://max.ru")
+				row := k.AddRow()
+				for i := 0; i < MaxButtonsPerRow+1; i++ {
+					row.AddContact("contact")
+				}
+				return NewMessage().SetChat(1).SetText("buttons").AddKeyboard(k)
+			},
+			fields: []string{
+				"attachments[0].buttons[0][0].text",
+				"attachments[0].buttons[0][0].payload",
+				"attachments[0].buttons[0][1].text",
+				"attachments[0].buttons[1]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.message().Validate()
+			if tt.fields == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var verr *ValidationError
+			require.ErrorAs(t, err, &verr)
+			fields := make([]string, 0, len(verr.Violations))
+			for _, v := range verr.Violations {
+				fields = append(fields, v.Field)
+			}
+			require.Equal(t, tt.fields, fields)
+		})
+	}
+}